@@ -0,0 +1,83 @@
+package flags
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlias(t *testing.T) {
+	newSet := func() (*FlagSet, *pflag.FlagSet, *string) {
+		var s FlagSet
+		var addr string
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		s.Add(fs, New(&addr, "nsq-nsqd-addr", "The address", Alias("nsq-addr"), Default("127.0.0.1:4150")))
+		return &s, fs, &addr
+	}
+
+	t.Run("alias", func(t *testing.T) {
+		s, fs, addr := newSet()
+		require.NoError(t, fs.Parse([]string{"--nsq-addr", "10.0.0.1:4150"}))
+		require.NoError(t, s.Check())
+		require.Equal(t, "10.0.0.1:4150", *addr)
+
+		origin, _, _ := s.Origin("nsq-nsqd-addr")
+		require.Equal(t, OriginFlag, origin)
+		require.True(t, fs.Lookup("nsq-addr").Hidden)
+	})
+
+	t.Run("same value", func(t *testing.T) {
+		s, fs, addr := newSet()
+		require.NoError(t, fs.Parse([]string{"--nsq-addr", "10.0.0.1:4150", "--nsq-nsqd-addr", "10.0.0.1:4150"}))
+		require.NoError(t, s.Check())
+		require.Equal(t, "10.0.0.1:4150", *addr)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		s, fs, _ := newSet()
+		require.NoError(t, fs.Parse([]string{"--nsq-addr", "10.0.0.1:4150", "--nsq-nsqd-addr", "10.0.0.2:4150"}))
+		require.EqualError(t, s.Check(), "--nsq-nsqd-addr and --nsq-addr (deprecated) have conflicting values")
+	})
+}
+
+func TestEnvAlias(t *testing.T) {
+	newSet := func(out io.Writer) (*FlagSet, *pflag.FlagSet, *string) {
+		var s FlagSet
+		var pass string
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.SetOutput(out)
+		s.Add(fs, New(&pass, "db-pass", "The password", Env("TEST_PG_PASSWORD"), EnvAlias("TEST_DB_PASSWORD"), Reloadable()))
+		return &s, fs, &pass
+	}
+
+	t.Run("alias", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "old")
+
+		var out bytes.Buffer
+		s, fs, pass := newSet(&out)
+		require.NoError(t, fs.Parse(nil))
+		require.NoError(t, s.Check())
+		require.Equal(t, "old", *pass)
+
+		_, key, _ := s.Origin("db-pass")
+		require.Equal(t, "TEST_DB_PASSWORD", key)
+
+		// The warning is only printed once.
+		require.NoError(t, s.Reload())
+		require.Equal(t, "Environment variable TEST_DB_PASSWORD has been deprecated, use TEST_PG_PASSWORD instead\n", out.String())
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "old")
+		t.Setenv("TEST_PG_PASSWORD", "new")
+
+		s, fs, pass := newSet(io.Discard)
+		require.NoError(t, fs.Parse(nil))
+		require.Equal(t, "new", *pass)
+		require.EqualError(t, s.Check(), `environment variables TEST_PG_PASSWORD and TEST_DB_PASSWORD (deprecated) have conflicting values for "db-pass"`)
+	})
+}
@@ -0,0 +1,154 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/spf13/pflag"
+)
+
+// Bind registers a flag for each tagged field of the struct pointed to by cfg.
+// The following struct tags are recognized:
+//
+//	flag      the flag name. Fields without a flag tag are ignored.
+//	env       the environment variable to read the value from.
+//	default   the default value, parsed the same way as the environment variable.
+//	required  "true" marks the flag as required.
+//	usage     the usage text.
+//	sep       the separator for slice and map values.
+//
+// Nested structs that aren't themselves a registered type are traversed. If a
+// nested struct field has a flag tag, it is used as a prefix for the names of
+// the flags within it, joined with a "-". Likewise its env tag, or its flag tag
+// converted to upper case, is used as a prefix for environment variables,
+// joined with a "_".
+//
+// If cfg embeds a FlagSet, the flags are added to it and it is returned,
+// otherwise a new FlagSet is returned. Invalid tags, unsupported field types and
+// duplicate flag names are reported as errors and nothing is registered.
+func Bind(fs *pflag.FlagSet, cfg any) (*FlagSet, error) {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cfg must be a pointer to a struct, got %T", cfg)
+	}
+
+	s := embeddedFlagSet(rv.Elem())
+	if s == nil {
+		s = &FlagSet{}
+	}
+
+	b := binder{
+		fs:         fs,
+		namePrefix: s.namePrefix,
+		seen:       make(map[string]bool),
+	}
+	b.bind(rv.Elem(), rv.Elem().Type().Name(), "", "")
+
+	if err := errors.Join(b.errs...); err != nil {
+		return nil, err
+	}
+
+	s.Add(fs, b.flags...)
+
+	return s, nil
+}
+
+func embeddedFlagSet(v reflect.Value) *FlagSet {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Anonymous && t.Field(i).Type == reflect.TypeOf(FlagSet{}) {
+			return v.Field(i).Addr().Interface().(*FlagSet)
+		}
+	}
+	return nil
+}
+
+type binder struct {
+	fs         *pflag.FlagSet
+	namePrefix string
+	seen       map[string]bool
+	flags      []*flag
+	errs       []error
+}
+
+func (b *binder) bind(v reflect.Value, path, namePrefix, envPrefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Type == reflect.TypeOf(FlagSet{}) {
+			continue
+		}
+
+		name := field.Tag.Get("flag")
+		if name == "-" {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		dest := v.Field(i).Addr().Interface()
+
+		if _, ok := lookupType(dest); !ok && field.Type.Kind() == reflect.Struct {
+			np, ep := namePrefix, envPrefix
+			if name != "" {
+				np += name + "-"
+				env := field.Tag.Get("env")
+				if env == "" {
+					env = envName(name)
+				}
+				ep += env + "_"
+			}
+			b.bind(v.Field(i), fieldPath, np, ep)
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		if err := b.add(field, dest, namePrefix+name, envPrefix); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("%s: %w", fieldPath, err))
+		}
+	}
+}
+
+func (b *binder) add(field reflect.StructField, dest any, name, envPrefix string) error {
+	fi, ok := lookupType(dest)
+	if !ok {
+		return fmt.Errorf("unsupported type %s", field.Type)
+	}
+
+	if b.seen[name] || b.fs.Lookup(b.namePrefix+name) != nil {
+		return fmt.Errorf("flag %q already defined", name)
+	}
+	b.seen[name] = true
+
+	f := New(dest, name, field.Tag.Get("usage"))
+
+	if env := field.Tag.Get("env"); env != "" {
+		f.envVar = envPrefix + env
+	}
+
+	f.separator = field.Tag.Get("sep")
+
+	if str, ok := field.Tag.Lookup("default"); ok {
+		val, err := fi.parse(f, str)
+		if err != nil {
+			return fmt.Errorf("invalid default %q: %w", str, err)
+		}
+		f.defaultValue = val
+	}
+
+	if str, ok := field.Tag.Lookup("required"); ok {
+		required, err := strconv.ParseBool(str)
+		if err != nil {
+			return fmt.Errorf("invalid required %q: %w", str, err)
+		}
+		f.required = required
+	}
+
+	b.flags = append(b.flags, f)
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package flags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type bindDBConfig struct {
+	Host string `flag:"host" env:"HOST" default:"127.0.0.1" required:"true" usage:"Database host"`
+	Port int    `flag:"port" env:"PORT" default:"5432" usage:"Database port"`
+}
+
+type bindConfig struct {
+	Primary  bindDBConfig  `flag:"primary"`
+	Replica  bindDBConfig  `flag:"replica" env:"RO"`
+	Topics   []string      `flag:"topics" env:"TOPICS" default:"a b" sep:" " usage:"Topics"`
+	Timeout  time.Duration `flag:"timeout" default:"5s"`
+	Verbose  bool          `flag:"verbose"`
+	Ignored  string
+	Excluded string `flag:"-"`
+
+	FlagSet
+}
+
+func TestBind(t *testing.T) {
+	t.Setenv("PRIMARY_HOST", "db1")
+	t.Setenv("RO_PORT", "6432")
+
+	var cfg bindConfig
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	s, err := Bind(fs, &cfg)
+	require.NoError(t, err)
+	require.Same(t, &cfg.FlagSet, s)
+	require.Len(t, cfg.flags, 7)
+
+	require.NoError(t, fs.Parse([]string{"--replica-host", "db2", "--verbose"}))
+	require.NoError(t, cfg.Check())
+
+	require.Equal(t, bindConfig{
+		Primary: bindDBConfig{Host: "db1", Port: 5432},
+		Replica: bindDBConfig{Host: "db2", Port: 6432},
+		Topics:  []string{"a", "b"},
+		Timeout: 5 * time.Second,
+		Verbose: true,
+		FlagSet: cfg.FlagSet,
+	}, cfg)
+
+	require.Nil(t, fs.Lookup("ignored"))
+	require.Nil(t, fs.Lookup("excluded"))
+}
+
+func TestBindErrors(t *testing.T) {
+	var cfg struct {
+		Port     int        `flag:"port" default:"abc"`
+		Required string     `flag:"name" required:"yes"`
+		Dup      string     `flag:"name"`
+		Complex  complex128 `flag:"complex"`
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	_, err := Bind(fs, &cfg)
+	require.EqualError(t, err, `.Port: invalid default "abc": strconv.ParseInt: parsing "abc": invalid syntax
+.Required: invalid required "yes": strconv.ParseBool: parsing "yes": invalid syntax
+.Dup: flag "name" already defined
+.Complex: unsupported type complex128`)
+
+	_, err = Bind(fs, cfg)
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package flags
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes, such as a memory limit. As a flag it accepts
+// a number followed by an optional unit, e.g. "512MiB" or "1.5GB". Units are
+// case insensitive. Decimal units (kB, MB, GB, TB, PB, EB) are powers of 1000,
+// binary units (KiB, MiB, GiB, TiB, PiB, EiB, or Ki, Mi, etc.) are powers of
+// 1024, and a number without a unit, or with B, is in bytes.
+type ByteSize uint64
+
+// Binary and decimal multiples of a byte.
+const (
+	Byte ByteSize = 1
+
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+	EiB = 1024 * PiB
+
+	KB = 1000 * Byte
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+	PB = 1000 * TB
+	EB = 1000 * PB
+)
+
+var byteUnits = map[string]ByteSize{
+	"":  Byte,
+	"b": Byte,
+
+	"kib": KiB, "ki": KiB,
+	"mib": MiB, "mi": MiB,
+	"gib": GiB, "gi": GiB,
+	"tib": TiB, "ti": TiB,
+	"pib": PiB, "pi": PiB,
+	"eib": EiB, "ei": EiB,
+
+	"kb": KB,
+	"mb": MB,
+	"gb": GB,
+	"tb": TB,
+	"pb": PB,
+	"eb": EB,
+}
+
+// formatUnits are tried in order when formatting a ByteSize.
+var formatUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"EiB", EiB}, {"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"EB", EB}, {"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"kB", KB},
+}
+
+func init() {
+	registerValue("size", ignoreFlag(ParseByteSize), ByteSize.String, nil, nonZero[ByteSize])
+}
+
+// ParseByteSize parses a size such as "512MiB". See ByteSize for the accepted
+// units.
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(str)
+	}
+
+	num, unit := str[:i], strings.ToLower(strings.TrimSpace(str[i:]))
+
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, str[i:])
+	}
+
+	if n, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if n > math.MaxUint64/uint64(mult) {
+			return 0, fmt.Errorf("invalid size %q: out of range", s)
+		}
+		return ByteSize(n) * mult, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	size := f * float64(mult)
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid size %q: out of range", s)
+	}
+
+	return ByteSize(size), nil
+}
+
+// String formats b using the largest unit that represents it exactly, e.g.
+// "512MiB", falling back to bytes, e.g. "1500B".
+func (b ByteSize) String() string {
+	if b == 0 {
+		return "0"
+	}
+
+	for _, u := range formatUnits {
+		if b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
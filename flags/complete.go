@@ -0,0 +1,72 @@
+package flags
+
+// Completion describes how shells complete a flag's value. Use one of Values,
+// FileExtensions, Dirs or CompletionFunc to create one.
+type Completion struct {
+	// Values are offered as the flag's possible values.
+	Values []string
+	// Extensions limit file name completion to files with one of these
+	// extensions, e.g. "yaml", without the leading dot.
+	Extensions []string
+	// DirsOnly limits file name completion to directories.
+	DirsOnly bool
+	// Func returns the values to offer given the partial value typed so far.
+	Func func(toComplete string) []string
+}
+
+// Values completes the flag's value with one of values, e.g. the values of an
+// enum.
+func Values(values ...string) Completion {
+	return Completion{Values: values}
+}
+
+// FileExtensions completes the flag's value with the names of files with one of
+// the extensions, e.g. "yaml" or "pem".
+func FileExtensions(exts ...string) Completion {
+	return Completion{Extensions: exts}
+}
+
+// Dirs completes the flag's value with the names of directories.
+func Dirs() Completion {
+	return Completion{DirsOnly: true}
+}
+
+// CompletionFunc completes the flag's value with the values returned by fn,
+// which is called with the partial value typed so far.
+func CompletionFunc(fn func(toComplete string) []string) Completion {
+	return Completion{Func: fn}
+}
+
+// Complete sets how shells complete the flag's value. Flags with a OneOf
+// validator complete its values unless Complete is given. The completions are
+// registered with cobra by root.Command.Register.
+func Complete(c Completion) Option {
+	return func(o *flag) {
+		o.completion = &c
+	}
+}
+
+// completionFor returns the flag's completion, if it has one.
+func (f *flag) completionFor() (Completion, bool) {
+	if f.completion != nil {
+		return *f.completion, true
+	}
+
+	for _, v := range f.validators {
+		if values, ok := v.(oneOfValidator); ok {
+			return Values(values...), true
+		}
+	}
+
+	return Completion{}, false
+}
+
+// VisitCompletions calls fn with the name of every flag in the set that has a
+// completion, either given with Complete or derived from a OneOf validator.
+func (s *FlagSet) VisitCompletions(fn func(name string, c Completion)) {
+	for _, f := range s.flags {
+		if c, ok := f.completionFor(); ok {
+			fn(f.name, c)
+		}
+	}
+}
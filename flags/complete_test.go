@@ -0,0 +1,38 @@
+package flags
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisitCompletions(t *testing.T) {
+	var mode, cert, dir, region, name string
+
+	var s FlagSet
+	s.Configure(NamePrefix("db-"))
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(
+		fs,
+		New(&mode, "ssl-mode", "SSL mode.", Validate(OneOf("disable", "require"))),
+		New(&cert, "cert", "Certificate.", Complete(FileExtensions("pem", "crt"))),
+		New(&dir, "dir", "Directory.", Complete(Dirs())),
+		New(&region, "region", "Region.", Complete(CompletionFunc(func(toComplete string) []string {
+			return []string{strings.ToUpper(toComplete)}
+		}))),
+		New(&name, "name", "Name."),
+	)
+
+	got := make(map[string]Completion)
+	s.VisitCompletions(func(name string, c Completion) {
+		got[name] = c
+	})
+
+	require.Len(t, got, 4)
+	require.Equal(t, []string{"disable", "require"}, got["db-ssl-mode"].Values)
+	require.Equal(t, []string{"pem", "crt"}, got["db-cert"].Extensions)
+	require.True(t, got["db-dir"].DirsOnly)
+	require.Equal(t, []string{"EU"}, got["db-region"].Func("eu"))
+}
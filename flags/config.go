@@ -0,0 +1,400 @@
+package flags
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jasonhancock/go-helpers"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFlag is the name of the flag added by AddConfigFlag.
+const ConfigFlag = "config"
+
+// ConfigEnv is the environment variable that can be used to specify the
+// config file instead of the flag added by AddConfigFlag. It is read with the
+// FlagSet's LookupEnv function when the flags are loaded.
+const ConfigEnv = "CONFIG_FILE"
+
+// AddConfigFlag adds the --config flag to fs. When it is set, values for flags
+// that weren't specified on the command line or by an environment variable are
+// read from the file, which can be YAML (.yaml or .yml), JSON (.json) or TOML
+// (.toml). The keys in the file are the flag names. Nested objects are joined
+// with a "-", so these are equivalent:
+//
+//	db-host: 127.0.0.1
+//
+//	db:
+//	  host: 127.0.0.1
+//
+// The flag is looked up by name on the pflag.FlagSet each flag was added to,
+// so adding it to a cobra command's persistent flags makes it available to all
+// of the subcommands.
+func AddConfigFlag(fs *pflag.FlagSet) {
+	fs.String(
+		ConfigFlag,
+		"",
+		helpers.EnvDesc("Path to a YAML, JSON or TOML config file.", ConfigEnv),
+	)
+}
+
+// Load applies values given on the command line with deprecated aliases, then
+// values from the sources set with Sources and finally values from the config
+// file to flags that weren't set on the command line or by a source. Sources
+// and the config file are only applied by the first call; after that, values
+// only change through Reload. It is called by Check, so most callers won't
+// need to call it directly. Keys in the config file that don't correspond to a
+// flag are reported as errors, unless the FlagSet has LenientConfig.
+func (s *FlagSet) Load() error {
+	mu := s.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	return s.load()
+}
+
+func (s *FlagSet) load() error {
+	var errs []error
+
+	for _, f := range s.flags {
+		if err := f.applyAliases(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	pass := make(sourcePass)
+	for _, f := range s.flags {
+		if f.sources == nil || f.loaded || f.fs.Lookup(f.name).Changed {
+			continue
+		}
+		f.loadSources(pass)
+	}
+
+	for _, fs := range s.pflagSets() {
+		path := s.configPath(fs)
+		if path == "" {
+			continue
+		}
+
+		cfg, err := s.readConfig(path)
+		if err != nil {
+			errs = append(errs, configError(path, "", err))
+			continue
+		}
+
+		errs = append(errs, s.unknownKeyErrors(path, cfg, fs)...)
+
+		for _, f := range s.flags {
+			if f.fs != fs || fs.Lookup(f.name).Changed {
+				continue
+			}
+			// Once loaded, values only change through Reload, which also
+			// replaces the cached config file.
+			if f.loaded {
+				if f.configErr != nil {
+					errs = append(errs, f.configErr)
+				}
+				continue
+			}
+			if f.origin != OriginDefault {
+				continue
+			}
+
+			val, ok := f.configValue(cfg)
+			if !ok {
+				continue
+			}
+
+			str := formatConfigValue(f, val)
+			if err := f.setString(str); err != nil {
+				f.configErr = f.parseError(SourceValue{Value: str, Origin: OriginFile, Key: path}, err)
+				errs = append(errs, f.configErr)
+				continue
+			}
+			f.origin, f.originKey = OriginFile, path
+		}
+	}
+
+	for _, f := range s.flags {
+		f.loaded = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// applyAliases copies the value given to a deprecated alias on the command line
+// to the flag.
+func (f *flag) applyAliases() error {
+	pf := f.fs.Lookup(f.name)
+	for i, alias := range f.aliases {
+		if !f.fs.Lookup(alias).Changed {
+			continue
+		}
+
+		val := reflect.ValueOf(f.aliasDests[i]).Elem()
+		dest := reflect.ValueOf(f.dest).Elem()
+		if pf.Changed && !reflect.DeepEqual(val.Interface(), dest.Interface()) {
+			err := fmt.Errorf("--%s and --%s (deprecated) have conflicting values", f.name, alias)
+			return f.sourceError(ReasonConflict, OriginFlag, "", "", err)
+		}
+
+		dest.Set(val)
+		pf.Changed = true
+	}
+
+	return nil
+}
+
+// configValue looks up the flag's value in cfg by its name, then by its
+// aliases.
+func (f *flag) configValue(cfg configFile) (any, bool) {
+	if val, ok := cfg.lookup(f.name); ok {
+		return val, true
+	}
+	for _, alias := range f.aliases {
+		if val, ok := cfg.lookup(alias); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// pflagSets returns the distinct pflag.FlagSets the flags were added to.
+func (s *FlagSet) pflagSets() []*pflag.FlagSet {
+	var sets []*pflag.FlagSet
+	seen := make(map[*pflag.FlagSet]bool)
+	for _, f := range s.flags {
+		if !seen[f.fs] {
+			seen[f.fs] = true
+			sets = append(sets, f.fs)
+		}
+	}
+	return sets
+}
+
+// configPath returns the config file given to fs with the flag added by
+// AddConfigFlag or, failing that, by the ConfigEnv environment variable.
+func (s *FlagSet) configPath(fs *pflag.FlagSet) string {
+	f := fs.Lookup(ConfigFlag)
+	if f == nil {
+		return ""
+	}
+	if !f.Changed {
+		if path, ok := s.getenv(ConfigEnv); ok {
+			return path
+		}
+	}
+	return f.Value.String()
+}
+
+// unknownKeyErrors reports the keys in cfg, read from path, that don't
+// correspond to a flag defined on fs or, with KnownConfigKeys, a known key.
+func (s *FlagSet) unknownKeyErrors(path string, cfg configFile, fs *pflag.FlagSet) []error {
+	if s.lenientConfig {
+		return nil
+	}
+
+	known := s.knownKey
+	if known == nil {
+		known = func(key string) bool { return fs.Lookup(key) != nil }
+	}
+
+	var errs []error
+	for _, key := range cfg.unknownKeys(known) {
+		errs = append(errs, configError(path, key, fmt.Errorf("%s: unknown key %q", path, key)))
+	}
+	return errs
+}
+
+func (s *FlagSet) readConfig(path string) (configFile, error) {
+	if cfg, ok := s.configs[path]; ok {
+		return cfg, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	cfg, err := parseConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.configs == nil {
+		s.configs = make(map[string]configFile)
+	}
+	s.configs[path] = cfg
+
+	if s.configStates == nil {
+		s.configStates = make(map[string]fileState)
+	}
+	s.configStates[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+
+	return cfg, nil
+}
+
+// parseConfigFile reads and decodes the config file at path.
+func parseConfigFile(path string) (configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	cfg := make(configFile)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, &cfg)
+	default:
+		err = fmt.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// setString parses str and stores the result in the flag's destination.
+func (f *flag) setString(str string) error {
+	fi, ok := lookupType(f.dest)
+	if !ok {
+		return fmt.Errorf("unsupported type %T", f.dest)
+	}
+
+	val, err := fi.parse(f, str)
+	if err != nil {
+		return err
+	}
+
+	reflect.ValueOf(f.dest).Elem().Set(reflect.ValueOf(val))
+	return nil
+}
+
+type configFile map[string]any
+
+// lookup finds the value for name, descending into nested objects whose keys
+// are prefixes of name.
+func (c configFile) lookup(name string) (any, bool) {
+	if val, ok := c[name]; ok {
+		return val, true
+	}
+
+	for key, val := range c {
+		rest, ok := strings.CutPrefix(name, key+"-")
+		if !ok {
+			continue
+		}
+
+		if nested, ok := asMap(val); ok {
+			if val, ok := configFile(nested).lookup(rest); ok {
+				return val, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// unknownKeys returns the keys that aren't known.
+func (c configFile) unknownKeys(known func(key string) bool) []string {
+	var unknown []string
+	c.walk("", func(key string) bool {
+		return key == ConfigFlag || known(key)
+	}, &unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+func (c configFile) walk(prefix string, known func(string) bool, unknown *[]string) {
+	for key, val := range c {
+		key = prefix + key
+		if known(key) {
+			continue
+		}
+
+		if nested, ok := asMap(val); ok {
+			configFile(nested).walk(key+"-", known, unknown)
+			continue
+		}
+
+		*unknown = append(*unknown, key)
+	}
+}
+
+// asMap converts a decoded object into a map[string]any. Decoders differ in the
+// map types they produce for nested objects.
+func asMap(val any) (map[string]any, bool) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+
+	m := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+
+	return m, true
+}
+
+// formatConfigValue converts a value decoded from a config file, or a flag's
+// default value, into a string that can be parsed by the flag's type.
+func formatConfigValue(f *flag, val any) string {
+	if m, ok := asMap(val); ok {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]string, 0, len(m))
+		for _, key := range keys {
+			items = append(items, key+"="+formatConfigValue(f, m[key]))
+		}
+		return strings.Join(items, f.sep())
+	}
+
+	if v, ok := val.(time.Time); ok {
+		return formatTime(f, v)
+	}
+
+	if v, ok := val.(net.IPNet); ok {
+		return v.String()
+	}
+
+	if m, ok := val.(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Slice {
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, formatConfigValue(f, rv.Index(i).Interface()))
+		}
+		return strings.Join(items, f.sep())
+	}
+
+	return fmt.Sprint(val)
+}
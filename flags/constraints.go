@@ -0,0 +1,225 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// constraint is a rule involving several flags that is enforced by Check. The
+// names are the ones the flags were created with, before any prefix set with
+// NamePrefix was applied.
+type constraint struct {
+	names []string
+	// check returns an error if the rule is broken. s is the set whose values
+	// are checked, and set reports whether a flag was given a value by the user.
+	check func(s *FlagSet, set func(name string) bool) error
+	// note is appended to the usage text of the named flag.
+	note func(name string) string
+}
+
+// MutuallyExclusive adds a constraint that at most one of the named flags may
+// be given a value. Flags holding their default value don't count.
+func (s *FlagSet) MutuallyExclusive(names ...string) {
+	s.addConstraint(constraint{
+		names: names,
+		check: func(_ *FlagSet, set func(string) bool) error {
+			given := filter(names, set)
+			if len(given) > 1 {
+				return fmt.Errorf("flags %s are mutually exclusive", s.flagList(given))
+			}
+			return nil
+		},
+		note: func(name string) string {
+			return "mutually exclusive with " + s.flagList(without(names, name))
+		},
+	})
+}
+
+// RequiredTogether adds a constraint that if any of the named flags is given a
+// value, all of them must be.
+func (s *FlagSet) RequiredTogether(names ...string) {
+	s.addConstraint(constraint{
+		names: names,
+		check: func(_ *FlagSet, set func(string) bool) error {
+			given := filter(names, set)
+			if len(given) > 0 && len(given) < len(names) {
+				missing := filter(names, func(name string) bool { return !set(name) })
+				return fmt.Errorf("flags %s must be specified together, missing %s", s.flagList(names), s.flagList(missing))
+			}
+			return nil
+		},
+		note: func(name string) string {
+			return "must be specified with " + s.flagList(without(names, name))
+		},
+	})
+}
+
+// AtLeastOneOf adds a constraint that at least one of the named flags must be
+// given a value.
+func (s *FlagSet) AtLeastOneOf(names ...string) {
+	s.addConstraint(constraint{
+		names: names,
+		check: func(_ *FlagSet, set func(string) bool) error {
+			if len(filter(names, set)) == 0 {
+				return fmt.Errorf("at least one of %s must be specified", s.flagList(names))
+			}
+			return nil
+		},
+		note: func(name string) string {
+			return "at least one of " + s.flagList(names) + " is required"
+		},
+	})
+}
+
+// Condition is a predicate used by RequiredIf.
+type Condition struct {
+	desc func(s *FlagSet) string
+	fn   func(s *FlagSet) bool
+}
+
+// When returns a Condition that calls fn. desc describes the condition in
+// usage text and errors, e.g. "TLS is enabled". It suits conditions on state
+// outside the FlagSet. fn can't see the new values while Reload validates them,
+// so conditions on reloadable flags should use IsSet, Equals or Satisfies.
+func When(desc string, fn func() bool) Condition {
+	return Condition{
+		desc: func(*FlagSet) string { return desc },
+		fn:   func(*FlagSet) bool { return fn() },
+	}
+}
+
+// Satisfies returns a Condition that is true when fn returns true for the named
+// flag's value, whose type T must match the flag's destination. desc describes
+// the condition as for When. During Reload, fn is called with the new value.
+func Satisfies[T any](name, desc string, fn func(val T) bool) Condition {
+	return Condition{
+		desc: func(*FlagSet) string { return desc },
+		fn: func(s *FlagSet) bool {
+			f := s.lookup(name)
+			if f == nil {
+				return false
+			}
+			val, ok := reflect.ValueOf(f.dest).Elem().Interface().(T)
+			return ok && fn(val)
+		},
+	}
+}
+
+// IsSet returns a Condition that is true when the named flag was given a value.
+func IsSet(name string) Condition {
+	return Condition{
+		desc: func(s *FlagSet) string {
+			return s.flagList([]string{name}) + " is set"
+		},
+		fn: func(s *FlagSet) bool {
+			f := s.lookup(name)
+			return f != nil && f.supplied()
+		},
+	}
+}
+
+// Equals returns a Condition that is true when the named flag's value, as
+// formatted by pflag, is value.
+func Equals(name, value string) Condition {
+	return Condition{
+		desc: func(s *FlagSet) string {
+			return fmt.Sprintf("%s is %q", s.flagList([]string{name}), value)
+		},
+		fn: func(s *FlagSet) bool {
+			f := s.lookup(name)
+			return f != nil && f.fs.Lookup(f.name).Value.String() == value
+		},
+	}
+}
+
+// RequiredIf adds a constraint that the named flag must be given a value when
+// cond is true.
+func (s *FlagSet) RequiredIf(name string, cond Condition) {
+	s.addConstraint(constraint{
+		names: []string{name},
+		check: func(values *FlagSet, set func(string) bool) error {
+			if cond.fn(values) && !set(name) {
+				return fmt.Errorf("%q is required when %s", s.namePrefix+name, cond.desc(s))
+			}
+			return nil
+		},
+		note: func(string) string {
+			return "required when " + cond.desc(s)
+		},
+	})
+}
+
+func (s *FlagSet) addConstraint(c constraint) {
+	for _, name := range c.names {
+		if s.lookup(name) == nil {
+			panic(fmt.Sprintf("unknown flag %q in constraint", name))
+		}
+	}
+
+	s.constraints = append(s.constraints, c)
+
+	for _, name := range c.names {
+		f := s.lookup(name)
+		f.notes = append(f.notes, c.note(name))
+		f.fs.Lookup(f.name).Usage = f.Usage()
+	}
+}
+
+func (s *FlagSet) checkConstraints() []error {
+	set := func(name string) bool {
+		return s.lookup(name).supplied()
+	}
+
+	var errs []error
+	for _, c := range s.constraints {
+		if err := c.check(s, set); err != nil {
+			names := make([]string, len(c.names))
+			for i, name := range c.names {
+				names[i] = s.namePrefix + name
+			}
+			errs = append(errs, &ValidationError{
+				Name:   names[0],
+				Flags:  names,
+				Reason: ReasonConstraint,
+				Err:    err,
+			})
+		}
+	}
+	return errs
+}
+
+// lookup finds a flag by the name it was created with, before any prefix set
+// with NamePrefix was applied.
+func (s *FlagSet) lookup(name string) *flag {
+	name = s.namePrefix + name
+	for _, f := range s.flags {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func filter(names []string, fn func(string) bool) []string {
+	var result []string
+	for _, name := range names {
+		if fn(name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func without(names []string, name string) []string {
+	return slices.DeleteFunc(slices.Clone(names), func(n string) bool { return n == name })
+}
+
+func (s *FlagSet) flagList(names []string) string {
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = "--" + s.namePrefix + name
+	}
+	return strings.Join(list, ", ")
+}
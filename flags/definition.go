@@ -0,0 +1,103 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// DefinitionError describes a flag that can't be added, such as one with an
+// unsupported type, a default value of the wrong type or a name that is already
+// taken.
+type DefinitionError struct {
+	// Name is the flag's name, including any prefix set with NamePrefix.
+	Name string
+	// File and Line are where the flag was created with New.
+	File string
+	Line int
+	Err  error
+}
+
+func (e *DefinitionError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("flag %q: %s", e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: flag %q: %s", e.File, e.Line, e.Name, e.Err)
+}
+
+func (e *DefinitionError) Unwrap() error {
+	return e.Err
+}
+
+// AddE is like Add, but instead of panicking it returns a *DefinitionError for
+// every flag that can't be added, joined with errors.Join. A panic in the Add
+// function of a type registered with RegisterType is returned as a
+// *DefinitionError too. If any flag can't be added, none of them are.
+func (s *FlagSet) AddE(fs *pflag.FlagSet, flags ...*flag) error {
+	var errs []error
+	taken := make(map[string]bool)
+	for _, f := range flags {
+		for _, err := range s.definitionErrors(fs, f, taken) {
+			errs = append(errs, &DefinitionError{
+				Name: s.namePrefix + f.name,
+				File: f.file,
+				Line: f.line,
+				Err:  err,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return s.add(fs, flags, true)
+}
+
+// withArticle prefixes the name of t with "a" or "an".
+func withArticle(t any) string {
+	name := fmt.Sprint(t)
+	if name != "" && strings.ContainsRune("aeioAEIO", rune(name[0])) {
+		return "an " + name
+	}
+	return "a " + name
+}
+
+// definitionErrors returns the reasons f can't be added to fs. taken holds the
+// names used by the flags checked before it.
+func (s *FlagSet) definitionErrors(fs *pflag.FlagSet, f *flag, taken map[string]bool) []error {
+	var errs []error
+
+	if _, ok := lookupType(f.dest); !ok {
+		errs = append(errs, fmt.Errorf("unsupported type %T", f.dest))
+	} else if f.defaultValue != nil {
+		want := reflect.TypeOf(f.dest).Elem()
+		if got := reflect.TypeOf(f.defaultValue); got != want {
+			errs = append(errs, fmt.Errorf("the flag is %s, but the default value is %s", withArticle(want), withArticle(got)))
+		}
+	}
+
+	names := append([]string{f.name}, f.aliases...)
+	for _, name := range names {
+		name = s.namePrefix + name
+		if taken[name] || fs.Lookup(name) != nil {
+			errs = append(errs, fmt.Errorf("flag --%s is already defined", name))
+		}
+		taken[name] = true
+	}
+
+	if f.shorthand != "" {
+		switch {
+		case len(f.shorthand) > 1:
+			errs = append(errs, fmt.Errorf("shorthand %q is more than one ASCII character", f.shorthand))
+		case taken["-"+f.shorthand] || fs.ShorthandLookup(f.shorthand) != nil:
+			errs = append(errs, fmt.Errorf("shorthand -%s is already defined", f.shorthand))
+		}
+		taken["-"+f.shorthand] = true
+	}
+
+	return errs
+}
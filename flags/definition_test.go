@@ -0,0 +1,134 @@
+package flags
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type panicky string
+
+func init() {
+	RegisterType(Type[panicky]{
+		Add: func(fs *pflag.FlagSet, p *panicky, name string, value panicky, usage string) {
+			panic("panicky flags can't be added")
+		},
+		Parse: func(s string) (panicky, error) {
+			return panicky(s), nil
+		},
+	})
+}
+
+func TestAddE(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		var host string
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		require.NoError(t, s.AddE(fs, New(&host, "host", "Host.", Default("localhost"))))
+		require.Equal(t, "localhost", host)
+		require.NotNil(t, fs.Lookup("host"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var c complex128
+		var port int64
+		var host, name string
+
+		var s FlagSet
+		s.Configure(NamePrefix("db-"))
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.String("db-taken", "", "")
+
+		err := s.AddE(
+			fs,
+			New(&c, "c", "A complex number."),
+			New(&port, "port", "Port.", Default(5432)),
+			New(&host, "host", "Host."),
+			New(&name, "taken", "Name.", Alias("host")),
+		)
+		require.Error(t, err)
+
+		var defErr *DefinitionError
+		require.True(t, errors.As(err, &defErr))
+		require.Equal(t, "db-c", defErr.Name)
+		require.Equal(t, "definition_test.go", filepath.Base(defErr.File))
+		require.NotZero(t, defErr.Line)
+
+		var msgs []string
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var defErr *DefinitionError
+			require.True(t, errors.As(err, &defErr))
+			msgs = append(msgs, defErr.Name+": "+defErr.Err.Error())
+		}
+		require.Equal(t, []string{
+			"db-c: unsupported type *complex128",
+			"db-port: the flag is an int64, but the default value is an int",
+			"db-taken: flag --db-taken is already defined",
+			"db-taken: flag --db-host is already defined",
+		}, msgs)
+
+		// Nothing was added.
+		require.Nil(t, fs.Lookup("db-host"))
+		require.Empty(t, s.flags)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		var host string
+		var p panicky
+
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		err := s.AddE(fs, New(&host, "host", "Host."), New(&p, "panicky", "Panics."))
+
+		var defErr *DefinitionError
+		require.True(t, errors.As(err, &defErr))
+		require.Equal(t, "panicky", defErr.Name)
+		require.EqualError(t, defErr.Err, "panicky flags can't be added")
+
+		// Nothing was added.
+		require.Nil(t, fs.Lookup("host"))
+		require.Empty(t, s.flags)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var host string
+		var p panicky
+
+		var s FlagSet
+		s.Configure(
+			NamePrefix("db-"),
+			EnvPrefix("APP_"),
+			LookupEnv(func(key string) (string, bool) { return "db1", key == "APP_HOST" }),
+		)
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		hostFlag := New(&host, "host", "Host.", Env("HOST"))
+		require.Error(t, s.AddE(fs, hostFlag, New(&p, "panicky", "Panics.")))
+
+		// The failed attempt left the flag untouched.
+		require.NoError(t, s.AddE(fs, hostFlag))
+		require.NotNil(t, fs.Lookup("db-host"))
+		require.Nil(t, fs.Lookup("db-db-host"))
+		require.Equal(t, "db1", host)
+	})
+
+	t.Run("shorthand", func(t *testing.T) {
+		var a, b, c string
+
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.StringP("verbose", "v", "", "")
+
+		err := s.AddE(
+			fs,
+			New(&a, "a", "A.", Shorthand("v")),
+			New(&b, "b", "B.", Shorthand("bb")),
+			New(&c, "c", "C.", Shorthand("b")),
+		)
+		require.ErrorContains(t, err, `flag "a": shorthand -v is already defined`)
+		require.ErrorContains(t, err, `flag "b": shorthand "bb" is more than one ASCII character`)
+		require.NotContains(t, err.Error(), `flag "c"`)
+	})
+}
@@ -0,0 +1,128 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// fileSuffix is appended to a secret flag's environment variable to find the
+// variable holding the path of a file containing the value.
+const fileSuffix = "_FILE"
+
+// lookupEnv returns the value of the flag's environment variable along with
+// the name of the variable it was read from. For secret flags, the value is
+// read from the file named by the "_FILE" variable if the variable itself
+// isn't set.
+func (f *flag) lookupEnv() (string, string, bool) {
+	if f.envVar == "" {
+		return "", "", false
+	}
+
+	if str, ok := f.getenv(f.envVar); ok {
+		for _, alias := range f.envAliases {
+			if aliasStr, ok := f.getenv(alias); ok && aliasStr != str {
+				err := fmt.Errorf("environment variables %s and %s (deprecated) have conflicting values for %q", f.envVar, alias, f.name)
+				f.errs = append(f.errs, f.sourceError(ReasonConflict, OriginEnv, alias, "", err))
+			}
+		}
+		return str, f.envVar, true
+	}
+
+	for _, alias := range f.envAliases {
+		if str, ok := f.getenv(alias); ok {
+			f.warnEnvAlias(alias)
+			return str, alias, true
+		}
+	}
+
+	if !f.secret {
+		return "", "", false
+	}
+
+	key := f.envVar + fileSuffix
+	path, ok := f.getenv(key)
+	if !ok {
+		return "", "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		err = fmt.Errorf("reading %s for %q: %w", key, f.name, err)
+		f.errs = append(f.errs, f.sourceError(ReasonParse, OriginEnv, key, "", err))
+		return "", "", false
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), key, true
+}
+
+// warnEnvAlias prints a deprecation warning for the environment variable
+// alias, like pflag does for deprecated flags. It is only printed once, not on
+// every Reload.
+func (f *flag) warnEnvAlias(alias string) {
+	if f.envAliasWarned {
+		return
+	}
+	f.envAliasWarned = true
+
+	out := io.Writer(os.Stderr)
+	if f.fs != nil {
+		out = f.fs.Output()
+	}
+	fmt.Fprintf(out, "Environment variable %s has been deprecated, use %s instead\n", alias, f.envVar)
+}
+
+func (s *FlagSet) getenv(key string) (string, bool) {
+	if s.lookupEnvFunc == nil {
+		return os.LookupEnv(key)
+	}
+	return s.lookupEnvFunc(key)
+}
+
+func (f *flag) getenv(key string) (string, bool) {
+	if f.lookupEnvFunc == nil {
+		return os.LookupEnv(key)
+	}
+	return f.lookupEnvFunc(key)
+}
+
+// PrintEnv writes a .env style template to w, listing the environment variable
+// of every flag that has one along with its default value, preceded by a
+// comment containing the flag's name and usage. The defaults of redacted flags
+// are left out.
+func (s *FlagSet) PrintEnv(w io.Writer) error {
+	for _, f := range s.flags {
+		if f.envVar == "" {
+			continue
+		}
+
+		comment := "--" + f.name
+		if f.usage != "" {
+			comment += ": " + f.usage
+		}
+		if f.required {
+			comment += " (required)"
+		}
+
+		var val string
+		if f.defaultValue != nil && !f.secret {
+			val = quoteEnv(formatConfigValue(f, f.defaultValue))
+		}
+
+		if _, err := fmt.Fprintf(w, "# %s\n%s=%s\n\n", comment, f.envVar, val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// quoteEnv quotes str if it would otherwise be misread in a .env file.
+func quoteEnv(str string) string {
+	if strings.ContainsAny(str, " \t\r\n#\"'\\$") {
+		return strconv.Quote(str)
+	}
+	return str
+}
@@ -0,0 +1,145 @@
+package flags
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("TEST_PASSWORD", "hunter2")
+
+		var pass string
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&pass, "password", "The password", Env("TEST_PASSWORD"), Secret()))
+
+		require.NoError(t, fs.Parse(nil))
+		require.Equal(t, "hunter2", pass)
+		require.NotContains(t, fs.FlagUsages(), "hunter2")
+		require.Equal(t, "********", s.Effective()[0].Value)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(path, []byte("hunter2\n\n"), 0600))
+		t.Setenv("TEST_PASSWORD_FILE", path)
+
+		var pass string
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&pass, "password", "The password", Env("TEST_PASSWORD"), Secret(), Required()))
+
+		require.NoError(t, fs.Parse(nil))
+		require.NoError(t, s.Check())
+		require.Equal(t, "hunter2", pass)
+
+		origin, key, _ := s.Origin("password")
+		require.Equal(t, OriginEnv, origin)
+		require.Equal(t, "TEST_PASSWORD_FILE", key)
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		t.Setenv("TEST_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		var pass string
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&pass, "password", "The password", Env("TEST_PASSWORD"), Secret()))
+
+		require.NoError(t, fs.Parse(nil))
+		err := s.Check()
+		require.Error(t, err)
+		require.True(t, strings.HasPrefix(err.Error(), `reading TEST_PASSWORD_FILE for "password": `))
+	})
+
+	t.Run("not secret", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(path, []byte("hunter2"), 0600))
+		t.Setenv("TEST_PASSWORD_FILE", path)
+
+		var pass string
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&pass, "password", "The password", Env("TEST_PASSWORD")))
+
+		require.NoError(t, fs.Parse(nil))
+		require.Empty(t, pass)
+	})
+}
+
+func TestMalformedEnv(t *testing.T) {
+	t.Setenv("TEST_PORT", "54x2")
+	t.Setenv("TEST_TIMEOUT", "soon")
+	t.Setenv("TEST_PIN", "12ab")
+
+	var (
+		s       FlagSet
+		port    int
+		timeout time.Duration
+		pin     int
+	)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(
+		fs,
+		New(&port, "port", "The port", Env("TEST_PORT"), Default(5432)),
+		New(&timeout, "timeout", "The timeout", Env("TEST_TIMEOUT")),
+		New(&pin, "pin", "The pin", Env("TEST_PIN"), Secret()),
+	)
+
+	require.NoError(t, fs.Parse(nil))
+	require.Equal(t, 5432, port)
+
+	err := s.Check()
+	require.Error(t, err)
+	require.Equal(t, []string{
+		`parsing environment variable TEST_PORT for "port": strconv.ParseInt: parsing "54x2": invalid syntax`,
+		`parsing environment variable TEST_TIMEOUT for "timeout": time: invalid duration "soon"`,
+		`parsing environment variable TEST_PIN for "pin": invalid value`,
+	}, strings.Split(err.Error(), "\n"))
+}
+
+func TestPrintEnv(t *testing.T) {
+	var host, pass, motd, ignored string
+	var hosts []string
+	var timeout time.Duration
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var s FlagSet
+	s.Add(
+		fs,
+		New(&host, "db-host", "Database host.", Env("DB_HOST"), Default("127.0.0.1"), Required()),
+		New(&pass, "db-pass", "Database password.", Env("DB_PASS"), Default("changeme"), Secret()),
+		New(&hosts, "db-replicas", "Replica hosts.", Env("DB_REPLICAS"), Default([]string{"a", "b"})),
+		New(&timeout, "db-timeout", "", Env("DB_TIMEOUT"), Default(5*time.Second)),
+		New(&motd, "motd", "Message of the day.", Env("MOTD"), Default("hello world")),
+		New(&ignored, "ignored", "Not settable from the environment."),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.PrintEnv(&buf))
+	require.Equal(t, `# --db-host: Database host. (required)
+DB_HOST=127.0.0.1
+
+# --db-pass: Database password.
+DB_PASS=
+
+# --db-replicas: Replica hosts.
+DB_REPLICAS=a,b
+
+# --db-timeout
+DB_TIMEOUT=5s
+
+# --motd: Message of the day.
+MOTD="hello world"
+
+`, buf.String())
+}
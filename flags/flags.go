@@ -3,26 +3,40 @@ package flags
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/jasonhancock/go-helpers"
 	"github.com/spf13/pflag"
 )
 
 type addFunc func(fs *pflag.FlagSet, f *flag)
+type parseFunc func(f *flag, s string) (any, error)
 type checkFunc func(f *flag) error
+type bindFunc func(fs *pflag.FlagSet, f *flag)
 
-var flagTypes = map[string]flagInfo{}
+var flagTypes = map[reflect.Type]flagInfo{}
 
 type flagInfo struct {
 	add   addFunc
+	parse parseFunc
 	check checkFunc
+	// bind defines the flag on fs with its current value, without looking it
+	// up in its sources.
+	bind bindFunc
 }
 
-func registerType(name string, add addFunc, check checkFunc) {
-	flagTypes[name] = flagInfo{
-		add:   add,
-		check: check,
+// lookupType finds how flags with the destination dest are handled: by a
+// registered type or, failing that, through pflag.Value or
+// encoding.TextUnmarshaler.
+func lookupType(dest any) (flagInfo, bool) {
+	if fi, ok := flagTypes[reflect.TypeOf(dest)]; ok {
+		return fi, true
 	}
+	return dynamicType(reflect.TypeOf(dest))
 }
 
 // Option is used to customize a flag.
@@ -42,10 +56,90 @@ func Default(value any) Option {
 	}
 }
 
-// Required marks the specified flag as being required.
+// Required marks the specified flag as being required. A required flag must
+// be given a value by the command line, an environment variable, a config file
+// or an explicit Default. Any value counts, including the zero value, so
+// --retries=0 or --enabled=false satisfy the requirement.
 func Required() Option {
 	return func(o *flag) {
 		o.required = true
+		o.nonZero = false
+	}
+}
+
+// RequiredNonZero marks the specified flag as being required, and requires its
+// value to be non-zero, regardless of where the value came from. Slices and
+// maps must have at least one element.
+func RequiredNonZero() Option {
+	return func(o *flag) {
+		o.required = true
+		o.nonZero = true
+	}
+}
+
+// Secret marks the flag as holding sensitive data. The value is hidden in the
+// effective configuration listing, the .env template written by PrintEnv and
+// validation errors, and its default is masked in the usage text. In addition,
+// if the flag has an environment variable that isn't set, the variable with a
+// "_FILE" suffix is checked for the path of a file containing the value,
+// following the Docker and Kubernetes secrets convention, and the usage text
+// mentions it. Trailing newlines are trimmed from the file's contents, and
+// failing to read the file is reported by Check.
+func Secret() Option {
+	return func(o *flag) {
+		o.secret = true
+	}
+}
+
+// Alias adds a deprecated name for the flag, e.g. its name before it was
+// renamed. The alias is hidden from the help output and using it prints a
+// deprecation warning. Giving the flag and its alias different values is
+// reported by Check.
+func Alias(name string) Option {
+	return func(o *flag) {
+		o.aliases = append(o.aliases, name)
+	}
+}
+
+// EnvAlias adds a deprecated environment variable for the flag that is used
+// when the flag's own environment variable isn't set. Using it prints a
+// deprecation warning. Setting both variables to different values is reported
+// by Check.
+func EnvAlias(name string) Option {
+	return func(o *flag) {
+		o.envAliases = append(o.envAliases, name)
+	}
+}
+
+// Shorthand sets a one letter abbreviation for the flag, used with a single
+// dash, e.g. -p for --port.
+func Shorthand(s string) Option {
+	return func(o *flag) {
+		o.shorthand = s
+	}
+}
+
+// Hidden hides the flag from the help output. It can still be used.
+func Hidden() Option {
+	return func(o *flag) {
+		o.hidden = true
+	}
+}
+
+// Deprecated hides the flag from the help output and prints msg when it is
+// used on the command line, e.g. "use --workers instead".
+func Deprecated(msg string) Option {
+	return func(o *flag) {
+		o.deprecated = msg
+	}
+}
+
+// ShorthandDeprecated prints msg when the flag's shorthand is used on the
+// command line. The flag itself is still shown in the help output, without the
+// shorthand.
+func ShorthandDeprecated(msg string) Option {
+	return func(o *flag) {
+		o.shorthandDeprecated = msg
 	}
 }
 
@@ -53,35 +147,140 @@ func Required() Option {
 func NotRequired() Option {
 	return func(o *flag) {
 		o.required = false
+		o.nonZero = false
 	}
 }
 
-func Validate(fn ValidationFunc) Option {
+// Validate adds validators to the flag. Validators are run by FlagSet.Check
+// against the flag's value whether or not the flag is required. They are
+// skipped for flags that aren't required and hold their zero value or an empty
+// slice or map, and for required flags that failed their required check.
+func Validate(validators ...Validator) Option {
 	return func(o *flag) {
-		o.required = false
+		o.validators = append(o.validators, validators...)
 	}
 }
 
+// Validator validates a flag's value. The value passed to Validate is the
+// dereferenced destination, e.g. a string for a flag created with a *string.
+// Validators that implement fmt.Stringer are described in the flag's usage
+// text.
+type Validator interface {
+	Validate(val any) error
+}
+
+// ValidationFunc is an adapter to allow the use of ordinary functions as
+// validators.
 type ValidationFunc func(val any) error
 
+// Validate calls fn(val).
+func (fn ValidationFunc) Validate(val any) error {
+	return fn(val)
+}
+
 type flag struct {
-	dest         any
-	name         string
-	envVar       string
-	defaultValue any
-	usage        string
-	required     bool
+	dest                any
+	name                string
+	envVar              string
+	defaultValue        any
+	usage               string
+	required            bool
+	nonZero             bool
+	validators          []Validator
+	separator           string
+	layouts             []string
+	reloadable          bool
+	secret              bool
+	aliases             []string
+	envAliases          []string
+	shorthand           string
+	hidden              bool
+	deprecated          string
+	shorthandDeprecated string
+	completion          *Completion
+
+	// lookupEnvFunc reads environment variables. If nil, os.LookupEnv is used.
+	lookupEnvFunc func(key string) (string, bool)
+	// sources are consulted for the flag's value. If nil, only the environment
+	// is.
+	sources []Source
+
+	// fs is the pflag.FlagSet the flag was added to.
+	fs *pflag.FlagSet
+	// origin and originKey record where the value came from, other than the
+	// command line, which is tracked by pflag.
+	origin    Origin
+	originKey string
+
+	// errs are errors encountered while adding the flag or looking up its
+	// sources, reported by Check.
+	errs []error
+
+	// loaded records that Load has applied the flag's sources and config file.
+	// configErr is the error, if any, from parsing its value in the config
+	// file, reported again by later calls.
+	loaded    bool
+	configErr error
+
+	// notes describe constraints involving the flag in its usage text.
+	notes []string
 
-	validationFn ValidationFunc
+	// aliasDests hold the values of the flags defined for aliases.
+	aliasDests []any
+
+	// envAliasWarned records that the deprecation warning for an environment
+	// variable alias has been printed.
+	envAliasWarned bool
+
+	// file and line are where New was called, for reporting definition errors.
+	file string
+	line int
 }
 
+// Usage returns the flag's usage text, followed by its environment variable,
+// whether it is required, descriptions of its validators and constraints and
+// any deprecated names. pflag appends the default value.
 func (f *flag) Usage() string {
-	// TODO: maybe throw in our default value?
-	if f.envVar == "" {
-		return f.usage
+	usage := f.usage
+	if f.envVar != "" {
+		usage = helpers.EnvDesc(usage, f.envVar)
+	}
+
+	var notes []string
+	if f.secret && f.envVar != "" {
+		notes = append(notes, "or the file named by "+f.envVar+fileSuffix)
+	}
+
+	if f.required {
+		notes = append(notes, "required")
+	}
+
+	for _, v := range f.validators {
+		if s, ok := v.(fmt.Stringer); ok {
+			notes = append(notes, s.String())
+		}
+	}
+
+	notes = append(notes, f.notes...)
+
+	if deprecated := f.deprecatedNames(); len(deprecated) > 0 {
+		notes = append(notes, "deprecated: "+strings.Join(deprecated, ", "))
+	}
+
+	if len(notes) > 0 {
+		usage += " (" + strings.Join(notes, "; ") + ")"
 	}
 
-	return helpers.EnvDesc(f.usage, f.envVar)
+	return usage
+}
+
+// deprecatedNames returns the flag's aliases and environment variable aliases.
+func (f *flag) deprecatedNames() []string {
+	names := make([]string, 0, len(f.aliases)+len(f.envAliases))
+	for _, alias := range f.aliases {
+		names = append(names, "--"+alias)
+	}
+	return append(names, f.envAliases...)
 }
 
 // New sets up a new flag.
@@ -92,6 +291,8 @@ func New(p any, name, usage string, opts ...Option) *flag {
 		usage: usage,
 	}
 
+	_, f.file, f.line, _ = runtime.Caller(1)
+
 	for _, opt := range opts {
 		opt(&f)
 	}
@@ -100,40 +301,214 @@ func New(p any, name, usage string, opts ...Option) *flag {
 }
 
 type FlagSet struct {
-	flags []*flag
+	flags       []*flag
+	constraints []constraint
+	configs     map[string]configFile
+	// configStates record the config files' state when they were read, so
+	// Watch can tell when they change.
+	configStates map[string]fileState
+
+	envPrefix     string
+	namePrefix    string
+	autoEnv       bool
+	lenientConfig bool
+	knownKey      func(key string) bool
+	lookupEnvFunc func(key string) (string, bool)
+	sources       []Source
+
+	// mu is held for writing while Load, Check and Reload update values. It is
+	// allocated when the first flags are added and held by pointer, so that a
+	// FlagSet embedded in a struct can still be copied.
+	mu          *sync.RWMutex
+	subscribers []func(changes []Change)
 }
 
+// Add adds the flags to fs. It panics if a flag's type isn't supported, its
+// default value is of the wrong type or its name or shorthand is already taken.
+// Use AddE to get an error instead.
 func (s *FlagSet) Add(fs *pflag.FlagSet, flags ...*flag) {
-	for i := range flags {
-		t := fmt.Sprintf("%T", flags[i].dest)
-		fi, ok := flagTypes[t]
+	// Without recovering, add can only fail by panicking.
+	_ = s.add(fs, flags, false)
+}
+
+// add adds the flags to fs. Each flag is defined on a scratch set first, and
+// the flags are only moved to fs once all of them have been defined, so nothing
+// is added if a type's add function panics. If recoverPanics is set, such a
+// panic is returned as a *DefinitionError.
+func (s *FlagSet) add(fs *pflag.FlagSet, flags []*flag, recoverPanics bool) error {
+	s.lock()
+
+	// The set's settings are applied to copies of the flags, so the ones passed
+	// in are left untouched if any of them can't be defined.
+	added := make([]*flag, len(flags))
+	scratch := make([]*pflag.FlagSet, len(flags))
+	for i, f := range flags {
+		fi, ok := lookupType(f.dest)
 		if !ok {
-			panic(fmt.Sprintf("unsupported type %s", t))
+			panic(fmt.Sprintf("unsupported type %T", f.dest))
 		}
-		fi.add(fs, flags[i])
+
+		f = f.copy()
+		s.applySettings(f)
+		f.fs = fs
+
+		var err error
+		if scratch[i], err = f.define(fs, fi, recoverPanics); err != nil {
+			return err
+		}
+		added[i] = f
+	}
+
+	for i, f := range added {
+		f.addTo(fs, scratch[i])
 	}
 
-	s.flags = append(s.flags, flags...)
+	s.flags = append(s.flags, added...)
+	return nil
 }
 
+// copy returns a copy of the flag that can be changed without affecting it.
+func (f *flag) copy() *flag {
+	g := *f
+	g.aliases = slices.Clone(f.aliases)
+	g.envAliases = slices.Clone(f.envAliases)
+	g.notes = slices.Clone(f.notes)
+	g.errs = slices.Clone(f.errs)
+	g.aliasDests = slices.Clone(f.aliasDests)
+	return &g
+}
+
+// define defines the flag and its aliases on a scratch set. pflag only
+// registers a shorthand when a flag is added, so they are moved to fs by addTo.
+func (f *flag) define(fs *pflag.FlagSet, fi flagInfo, recoverPanics bool) (scratch *pflag.FlagSet, err error) {
+	if recoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &DefinitionError{Name: f.name, File: f.file, Line: f.line, Err: fmt.Errorf("%v", r)}
+			}
+		}()
+	}
+
+	scratch = pflag.NewFlagSet("", pflag.ContinueOnError)
+	scratch.SetNormalizeFunc(fs.GetNormalizeFunc())
+	fi.add(scratch, f)
+	return scratch, nil
+}
+
+// addTo moves the flag and its aliases from the scratch set they were defined
+// on to fs and applies the options that map directly onto pflag's.
+func (f *flag) addTo(fs *pflag.FlagSet, scratch *pflag.FlagSet) {
+	pf := scratch.Lookup(f.name)
+	pf.Shorthand = f.shorthand
+	fs.AddFlag(pf)
+	for _, alias := range f.aliases {
+		fs.AddFlag(scratch.Lookup(alias))
+	}
+
+	if f.hidden {
+		pf.Hidden = true
+	}
+	if f.deprecated != "" {
+		pf.Deprecated = f.deprecated
+		pf.Hidden = true
+	}
+	if f.shorthandDeprecated != "" {
+		pf.ShorthandDeprecated = f.shorthandDeprecated
+	}
+}
+
+// Check applies values from the config file, if any, then verifies that all
+// required flags have been specified, runs each flag's validators and enforces
+// the constraints between flags. All failures are returned together.
 func (s *FlagSet) Check() error {
+	mu := s.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
 	var errs []error
 
+	if err := s.load(); err != nil {
+		errs = append(errs, err)
+	}
+
 	for _, f := range s.flags {
-		if !f.required {
-			continue
+		errs = append(errs, f.errs...)
+		errs = append(errs, f.validate()...)
+	}
+
+	errs = append(errs, s.checkConstraints()...)
+
+	return errors.Join(errs...)
+}
+
+// lock returns the set's mutex, allocating it if no flags have been added yet.
+func (s *FlagSet) lock() *sync.RWMutex {
+	if s.mu == nil {
+		s.mu = new(sync.RWMutex)
+	}
+	return s.mu
+}
+
+// validate checks that the flag has a value if it is required and runs its
+// validators.
+func (f *flag) validate() []error {
+	val := reflect.ValueOf(f.dest).Elem()
+
+	if f.required {
+		if err := f.checkRequired(); err != nil {
+			return []error{f.validationError(ReasonMissing, err)}
 		}
+	} else if isEmpty(val) {
+		return nil
+	}
 
-		t := fmt.Sprintf("%T", f.dest)
-		fi, ok := flagTypes[t]
-		if !ok {
-			panic(fmt.Sprintf("unsupported type %s", t))
+	var errs []error
+	for _, v := range f.validators {
+		if err := v.Validate(val.Interface()); err != nil {
+			// Validation errors usually contain the value.
+			if f.secret {
+				err = errors.New("invalid value")
+			}
+			err = fmt.Errorf("invalid value for %q: %w", f.name, err)
+			errs = append(errs, f.validationError(ReasonInvalid, err))
 		}
+	}
+	return errs
+}
 
-		if err := fi.check(f); err != nil {
-			errs = append(errs, err)
+func (f *flag) checkRequired() error {
+	if f.nonZero {
+		fi, ok := lookupType(f.dest)
+		if !ok {
+			panic(fmt.Sprintf("unsupported type %T", f.dest))
 		}
+		return fi.check(f)
 	}
 
-	return errors.Join(errs...)
+	if !f.isSet() {
+		return fmt.Errorf("required value %q not specified", f.name)
+	}
+
+	return nil
+}
+
+// isSet reports whether the flag's value was supplied by the command line, an
+// environment variable, a config file or an explicit default.
+func (f *flag) isSet() bool {
+	return f.defaultValue != nil || f.supplied()
+}
+
+// supplied reports whether the flag's value was supplied by the command line,
+// an environment variable or a config file.
+func (f *flag) supplied() bool {
+	return f.origin != OriginDefault || f.fs.Lookup(f.name).Changed
+}
+
+// isEmpty reports whether v holds its zero value or is an empty slice or map.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
 }
@@ -0,0 +1,226 @@
+package flags
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type region string
+
+func init() {
+	RegisterType(Type[region]{
+		Add: func(fs *pflag.FlagSet, p *region, name string, value region, usage string) {
+			fs.StringVar((*string)(p), name, string(value), usage)
+		},
+		Parse: func(s string) (region, error) {
+			if s != strings.ToLower(s) {
+				return "", errors.New("regions are lower case")
+			}
+			return region(s), nil
+		},
+		Check: nonZero[region],
+	})
+}
+
+func TestRegisterType(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		var r region
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&r, "region", "The region", Default(region("us-east-1")), Required()))
+
+		require.NoError(t, fs.Parse(nil))
+		require.Equal(t, region("us-east-1"), r)
+		require.NoError(t, s.Check())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("TEST_REGION", "eu-west-1")
+
+		var r region
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&r, "region", "The region", Env("TEST_REGION")))
+
+		require.NoError(t, fs.Parse(nil))
+		require.Equal(t, region("eu-west-1"), r)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		var r region
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		s.Add(fs, New(&r, "region", "The region", Required()))
+
+		require.NoError(t, fs.Parse(nil))
+		require.EqualError(t, s.Check(), `required value "region" not specified`)
+	})
+
+	t.Run("wrong default type", func(t *testing.T) {
+		var r region
+		var s FlagSet
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		require.Panics(t, func() {
+			s.Add(fs, New(&r, "region", "The region", Default("us-east-1")))
+		})
+	})
+}
+
+func TestUnsupportedType(t *testing.T) {
+	var c complex128
+	var s FlagSet
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.PanicsWithValue(t, "unsupported type *complex128", func() {
+		s.Add(fs, New(&c, "c", "A complex number"))
+	})
+}
+
+func TestCheckValidators(t *testing.T) {
+	var s FlagSet
+	var mode, optional, empty string
+	var port int
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(
+		fs,
+		New(&mode, "mode", "The mode", Default("bogus"), Validate(OneOf("a", "b"))),
+		New(&port, "port", "The port", Default(0), RequiredNonZero(), Validate(Range(1, 65535))),
+		New(&optional, "optional", "Optional", Validate(NotBlank(), ValidationFunc(func(val any) error {
+			return errors.New("always fails")
+		}))),
+		New(&empty, "empty", "Not required and empty", Validate(NotBlank())),
+	)
+
+	require.NoError(t, fs.Parse([]string{"--optional", " "}))
+
+	err := s.Check()
+	require.Error(t, err)
+	require.Equal(t, []string{
+		`invalid value for "mode": "bogus" is not one of a, b`,
+		`required value "port" not specified`,
+		`invalid value for "optional": value is blank`,
+		`invalid value for "optional": always fails`,
+	}, strings.Split(err.Error(), "\n"))
+}
+
+func TestRequired(t *testing.T) {
+	t.Setenv("TEST_ENABLED", "false")
+
+	var (
+		s                 FlagSet
+		retries, workers  int
+		enabled, verbose  bool
+		name, user, token string
+	)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(
+		fs,
+		New(&retries, "retries", "Retries", Required()),
+		New(&workers, "workers", "Workers", Default(0), Required()),
+		New(&enabled, "enabled", "Enabled", Env("TEST_ENABLED"), Required()),
+		New(&verbose, "verbose", "Verbose", Required()),
+		New(&name, "name", "Name", Required()),
+		New(&user, "user", "User", Default(""), RequiredNonZero()),
+		New(&token, "token", "Token", RequiredNonZero()),
+	)
+
+	require.NoError(t, fs.Parse([]string{"--retries=0", "--token", "abc"}))
+
+	err := s.Check()
+	require.Error(t, err)
+	require.Equal(t, []string{
+		`required value "verbose" not specified`,
+		`required value "name" not specified`,
+		`required value "user" not specified`,
+	}, strings.Split(err.Error(), "\n"))
+}
+
+func TestUsage(t *testing.T) {
+	t.Setenv("APP_MODE", "prod")
+	t.Setenv("APP_TOKEN", "hunter2")
+
+	var mode, token, name string
+	var port int
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var s FlagSet
+	s.Add(
+		fs,
+		New(&mode, "mode", "Run mode.", Env("APP_MODE"), Default("dev"), Validate(OneOf("dev", "prod"))),
+		New(&port, "port", "Port.", Default(8080), Required(), Validate(Range(1, 65535))),
+		New(&token, "token", "API token.", Env("APP_TOKEN"), Default("changeme"), Secret()),
+		New(&name, "name", "Name.", Alias("old-name"), EnvAlias("OLD_NAME")),
+	)
+
+	require.Equal(t, "Run mode. Can be set with the APP_MODE env variable (one of: dev, prod)", fs.Lookup("mode").Usage)
+	require.Equal(t, "dev", fs.Lookup("mode").DefValue)
+	require.Equal(t, "prod", mode)
+
+	require.Equal(t, "Port. (required; between 1 and 65535)", fs.Lookup("port").Usage)
+
+	require.Equal(t, redacted, fs.Lookup("token").DefValue)
+	require.Equal(t, "API token. Can be set with the APP_TOKEN env variable (or the file named by APP_TOKEN_FILE)", fs.Lookup("token").Usage)
+
+	require.Equal(t, "Name. (deprecated: --old-name, OLD_NAME)", fs.Lookup("name").Usage)
+
+	usages := fs.FlagUsages()
+	require.Contains(t, usages, `(default "dev")`)
+	require.Contains(t, usages, "(default 8080)")
+	require.NotContains(t, usages, "hunter2")
+	require.NotContains(t, usages, "changeme")
+}
+
+func TestPflagOptions(t *testing.T) {
+	var (
+		port    int
+		hosts   []string
+		reg     region
+		level   slog.Level
+		debug   bool
+		workers int
+	)
+
+	var out bytes.Buffer
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.SetOutput(&out)
+
+	var s FlagSet
+	s.Add(
+		fs,
+		New(&port, "port", "Port.", Shorthand("p")),
+		New(&hosts, "hosts", "Hosts.", Shorthand("H"), ShorthandDeprecated("use --hosts instead")),
+		New(&reg, "region", "Region.", Shorthand("r"), Alias("zone")),
+		New(&level, "log-level", "Log level.", Shorthand("l")),
+		New(&debug, "debug", "Debug internals.", Hidden()),
+		New(&workers, "threads", "Threads.", Deprecated("use --workers instead")),
+	)
+
+	require.NoError(t, fs.Parse([]string{"-p", "8080", "-H", "a,b", "-r", "eu", "-l", "debug", "--debug", "--threads", "4"}))
+	require.NoError(t, s.Check())
+
+	require.Equal(t, 8080, port)
+	require.Equal(t, []string{"a", "b"}, hosts)
+	require.Equal(t, region("eu"), reg)
+	require.Equal(t, slog.LevelDebug, level)
+	require.True(t, debug)
+	require.Equal(t, 4, workers)
+
+	require.Contains(t, out.String(), "Flag shorthand -H has been deprecated, use --hosts instead")
+	require.Contains(t, out.String(), "Flag --threads has been deprecated, use --workers instead")
+
+	usages := fs.FlagUsages()
+	require.Contains(t, usages, "-p, --port int")
+	require.Contains(t, usages, "-r, --region string")
+	require.Contains(t, usages, "--hosts strings")
+	require.NotContains(t, usages, "-H")
+	require.NotContains(t, usages, "--debug")
+	require.NotContains(t, usages, "--threads")
+	require.NotContains(t, usages, "--zone string")
+}
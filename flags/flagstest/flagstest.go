@@ -0,0 +1,99 @@
+// Package flagstest helps test code that defines flags with the flags package
+// without touching the process environment, so tests can run in parallel.
+package flagstest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jasonhancock/cobraflags/flags"
+	"github.com/spf13/pflag"
+)
+
+// Checker is implemented by anything embedding a flags.FlagSet.
+type Checker interface {
+	Check() error
+}
+
+// BuildFunc defines flags on fs, applying opts to the flags.FlagSet they are
+// added to, and returns the resulting config. nsq.NewConfig has this signature,
+// and other constructors can be adapted with a closure.
+type BuildFunc[T Checker] func(fs *pflag.FlagSet, opts ...flags.SetOption) T
+
+type options struct {
+	env        map[string]string
+	configExt  string
+	configData string
+	args       []string
+}
+
+// Option is used to customize Parse.
+type Option func(*options)
+
+// WithEnv sets the environment variables the flags see. No other variables are
+// visible, and the process environment is never read.
+func WithEnv(env map[string]string) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+// WithConfig writes content to a config file with the extension ext, e.g.
+// ".yaml", in a temporary directory and passes it with the --config flag.
+func WithConfig(ext, content string) Option {
+	return func(o *options) {
+		o.configExt, o.configData = ext, content
+	}
+}
+
+// WithArgs sets the command line arguments to parse.
+func WithArgs(args ...string) Option {
+	return func(o *options) {
+		o.args = args
+	}
+}
+
+// Env returns a flags.SetOption that makes a FlagSet read environment
+// variables from env instead of the process environment.
+func Env(env map[string]string) flags.SetOption {
+	return flags.LookupEnv(func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	})
+}
+
+// Parse calls build with a new pflag.FlagSet and an isolated environment,
+// parses the arguments and returns the config along with the error from its
+// Check method. Failures to set up the FlagSet or parse the arguments fail the
+// test.
+func Parse[T Checker](t testing.TB, build BuildFunc[T], opts ...Option) (T, error) {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fs := pflag.NewFlagSet(t.Name(), pflag.ContinueOnError)
+	cfg := build(fs, Env(o.env))
+
+	args := o.args
+	if o.configExt != "" {
+		path := filepath.Join(t.TempDir(), "config"+o.configExt)
+		if err := os.WriteFile(path, []byte(o.configData), 0o600); err != nil {
+			t.Fatalf("writing config file: %v", err)
+		}
+
+		if fs.Lookup(flags.ConfigFlag) == nil {
+			fs.String(flags.ConfigFlag, "", "")
+		}
+		args = append([]string{"--" + flags.ConfigFlag, path}, args...)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parsing arguments: %v", err)
+	}
+
+	return cfg, cfg.Check()
+}
@@ -0,0 +1,89 @@
+package flagstest
+
+import (
+	"testing"
+
+	"github.com/jasonhancock/cobraflags/flags"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Host     string
+	Port     int
+	Password string
+
+	flags.FlagSet
+}
+
+func newTestConfig(fs *pflag.FlagSet, opts ...flags.SetOption) *testConfig {
+	var c testConfig
+	c.Configure(opts...)
+	c.Add(
+		fs,
+		flags.New(&c.Host, "host", "Host.", flags.Env("HOST"), flags.Required()),
+		flags.New(&c.Port, "port", "Port.", flags.Env("PORT"), flags.Default(5432)),
+		flags.New(&c.Password, "password", "Password.", flags.Env("PASSWORD"), flags.Secret()),
+	)
+	return &c
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		host     string
+		port     int
+		password string
+		err      string
+	}{
+		{
+			name: "env",
+			opts: []Option{WithEnv(map[string]string{"HOST": "env-host", "PASSWORD": "hunter2"})},
+			host: "env-host", port: 5432, password: "hunter2",
+		},
+		{
+			name: "config",
+			opts: []Option{WithConfig(".yaml", "host: file-host\nport: 6432\n")},
+			host: "file-host", port: 6432,
+		},
+		{
+			name: "args",
+			opts: []Option{
+				WithEnv(map[string]string{"HOST": "env-host"}),
+				WithArgs("--host", "flag-host"),
+			},
+			host: "flag-host", port: 5432,
+		},
+		{
+			name: "missing",
+			port: 5432,
+			err:  `required value "host" not specified`,
+		},
+		{
+			name: "invalid",
+			opts: []Option{WithEnv(map[string]string{"HOST": "h", "PORT": "abc"})},
+			host: "h",
+			port: 5432,
+			err:  `parsing environment variable PORT for "port": strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := Parse(t, newTestConfig, tt.opts...)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.host, cfg.Host)
+			require.Equal(t, tt.port, cfg.Port)
+			require.Equal(t, tt.password, cfg.Password)
+		})
+	}
+}
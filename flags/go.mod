@@ -3,8 +3,15 @@ module github.com/jasonhancock/cobraflags/flags
 go 1.22.0
 
 require (
-	github.com/jasonhancock/go-env v0.0.6
+	github.com/BurntSushi/toml v1.4.0
 	github.com/jasonhancock/go-helpers v0.0.6
-	github.com/spf13/pflag v1.0.5
+	github.com/spf13/pflag v1.0.6
+	github.com/stretchr/testify v1.9.0
 	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 )
@@ -0,0 +1,71 @@
+package flags
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	RegisterType(Type[net.IP]{
+		Add:   (*pflag.FlagSet).IPVar,
+		Parse: parseIP,
+		Check: nonEmpty[net.IP],
+	})
+
+	RegisterType(Type[net.IPNet]{
+		Add:   (*pflag.FlagSet).IPNetVar,
+		Parse: parseIPNet,
+		Check: func(name string, val net.IPNet) error {
+			if val.IP == nil {
+				return fmt.Errorf("required value %q not specified", name)
+			}
+			return nil
+		},
+	})
+
+	registerValue("url", ignoreFlag(parseURL), formatURL, redactURL, nonZero[*url.URL])
+}
+
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+func parseIPNet(s string) (net.IPNet, error) {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	return *n, nil
+}
+
+// parseURL parses a URL. An empty string results in a nil URL.
+func parseURL(s string) (*url.URL, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return url.Parse(s)
+}
+
+// formatURL formats u, including any password, so that it can be compared and
+// parsed again.
+func formatURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// redactURL formats u for display, with any password redacted.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,132 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Origin identifies where a flag's value came from.
+type Origin int
+
+const (
+	// OriginDefault means the flag has its default value.
+	OriginDefault Origin = iota
+	// OriginEnv means the value was read from an environment variable.
+	OriginEnv
+	// OriginFile means the value was read from a config file.
+	OriginFile
+	// OriginFlag means the value was specified on the command line.
+	OriginFlag
+	// OriginSource means the value was read from a Source other than the
+	// environment or a file.
+	OriginSource
+)
+
+func (o Origin) String() string {
+	switch o {
+	case OriginDefault:
+		return "default"
+	case OriginEnv:
+		return "env"
+	case OriginFile:
+		return "file"
+	case OriginFlag:
+		return "flag"
+	case OriginSource:
+		return "source"
+	}
+	return fmt.Sprintf("Origin(%d)", int(o))
+}
+
+// redacted replaces the values of redacted flags.
+const redacted = "********"
+
+// EffectiveValue describes a flag's final value and where it came from.
+type EffectiveValue struct {
+	Name string
+	// Value is the flag's value formatted as a string. It is redacted for
+	// flags created with the Secret option.
+	Value  string
+	Origin Origin
+	// Key is the environment variable or config file path the value was read
+	// from, if any.
+	Key string
+}
+
+// Describe returns a human readable description of where the value came from,
+// e.g. "env DB_HOST".
+func (v EffectiveValue) Describe() string {
+	if v.Key == "" {
+		return v.Origin.String()
+	}
+	return v.Origin.String() + " " + v.Key
+}
+
+// Origin returns where the named flag's value came from, along with the
+// environment variable or config file path it was read from, if any. Values
+// from config files are only known after Load or Check has been called.
+func (s *FlagSet) Origin(name string) (Origin, string, bool) {
+	mu := s.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+
+	f := s.lookup(name)
+	if f == nil {
+		return 0, "", false
+	}
+
+	v := f.effective()
+	return v.Origin, v.Key, true
+}
+
+// Effective returns the effective value of every flag in the set, in the order
+// they were added. Values from config files are only included after Load or
+// Check has been called.
+func (s *FlagSet) Effective() []EffectiveValue {
+	mu := s.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+
+	values := make([]EffectiveValue, 0, len(s.flags))
+	for _, f := range s.flags {
+		values = append(values, f.effective())
+	}
+	return values
+}
+
+// PrintEffective writes the effective configuration as a table to w.
+func (s *FlagSet) PrintEffective(w io.Writer) error {
+	return PrintEffectiveValues(w, s.Effective())
+}
+
+// PrintEffectiveValues writes values as a table to w, like PrintEffective. It
+// is useful for combining the effective configuration of several FlagSets.
+func PrintEffectiveValues(w io.Writer, values []EffectiveValue) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tVALUE\tSOURCE")
+	for _, v := range values {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Name, v.Value, v.Describe())
+	}
+	return tw.Flush()
+}
+
+func (f *flag) effective() EffectiveValue {
+	v := EffectiveValue{
+		Name:   f.name,
+		Origin: f.origin,
+		Key:    f.originKey,
+	}
+
+	pf := f.fs.Lookup(f.name)
+	if pf.Changed {
+		v.Origin, v.Key = OriginFlag, ""
+	}
+
+	v.Value = displayValue(pf.Value)
+	if f.secret && v.Value != "" {
+		v.Value = redacted
+	}
+
+	return v
+}
@@ -0,0 +1,60 @@
+package flags
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffective(t *testing.T) {
+	t.Setenv("TEST_DB_USER", "env-user")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("db-name: file-name\n"), 0600))
+
+	var (
+		s                      FlagSet
+		host, user, name, pass string
+	)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlag(fs)
+	s.Add(
+		fs,
+		New(&host, "db-host", "Host", Default("127.0.0.1")),
+		New(&user, "db-user", "User", Env("TEST_DB_USER")),
+		New(&name, "db-name", "Name"),
+		New(&pass, "db-pass", "Password", Secret()),
+	)
+
+	require.NoError(t, fs.Parse([]string{"--config", path, "--db-pass", "secret"}))
+	require.NoError(t, s.Check())
+
+	require.Equal(t, []EffectiveValue{
+		{Name: "db-host", Value: "127.0.0.1", Origin: OriginDefault},
+		{Name: "db-user", Value: "env-user", Origin: OriginEnv, Key: "TEST_DB_USER"},
+		{Name: "db-name", Value: "file-name", Origin: OriginFile, Key: path},
+		{Name: "db-pass", Value: "********", Origin: OriginFlag},
+	}, s.Effective())
+
+	origin, key, ok := s.Origin("db-user")
+	require.True(t, ok)
+	require.Equal(t, OriginEnv, origin)
+	require.Equal(t, "TEST_DB_USER", key)
+
+	_, _, ok = s.Origin("nope")
+	require.False(t, ok)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.PrintEffective(&buf))
+	require.Equal(t, `FLAG     VALUE      SOURCE
+db-host  127.0.0.1  default
+db-user  env-user   env TEST_DB_USER
+db-name  file-name  file `+path+`
+db-pass  ********   flag
+`, buf.String())
+}
@@ -0,0 +1,25 @@
+package flags
+
+import (
+	"regexp"
+)
+
+func init() {
+	registerValue("regexp", ignoreFlag(parseRegexp), formatRegexp, nil, nonZero[*regexp.Regexp])
+}
+
+// parseRegexp compiles a regular expression. An empty string results in a nil
+// *regexp.Regexp rather than one that matches everything.
+func parseRegexp(s string) (*regexp.Regexp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return regexp.Compile(s)
+}
+
+func formatRegexp(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
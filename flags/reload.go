@@ -0,0 +1,341 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultWatchInterval is how often Watch checks whether config files have
+// changed when no WatchInterval option is given.
+const DefaultWatchInterval = 5 * time.Second
+
+// Reloadable marks the flag as one whose value is updated by FlagSet.Reload.
+// The values of other flags never change once the program has started.
+func Reloadable() Option {
+	return func(o *flag) {
+		o.reloadable = true
+	}
+}
+
+// Change describes a reloadable flag whose value was changed by Reload. Old
+// and New are the dereferenced destination before and after the reload.
+type Change struct {
+	Name string
+	Old  any
+	New  any
+}
+
+// Subscribe registers fn to be called after each reload that changes the value
+// of any reloadable flag.
+func (s *FlagSet) Subscribe(fn func(changes []Change)) {
+	mu := s.lock()
+	mu.Lock()
+	defer mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Read calls fn while holding a lock that prevents Reload from changing values
+// concurrently. Use it to read reloadable flags' destinations from other
+// goroutines.
+func (s *FlagSet) Read(fn func()) {
+	mu := s.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+	fn()
+}
+
+// Reload re-reads the environment and config files and updates the values of
+// reloadable flags that weren't given on the command line. The whole set is
+// then validated as Check does. If that fails, the previous values are kept and
+// the error is returned, otherwise the subscribers are notified of the flags
+// whose values changed.
+func (s *FlagSet) Reload() error {
+	mu := s.lock()
+	mu.Lock()
+	changes, err := s.reload()
+	subscribers := s.subscribers
+	mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("reloading configuration: %w", err)
+	}
+
+	if len(changes) > 0 {
+		for _, fn := range subscribers {
+			fn(changes)
+		}
+	}
+
+	return nil
+}
+
+func (s *FlagSet) reload() ([]Change, error) {
+	var errs []error
+
+	// The new values are read into copies of the reloadable flags, which are
+	// checked along with the rest of the set before any value is changed.
+	next := *s
+	next.configs, next.configStates = nil, nil
+	next.flags = slices.Clone(s.flags)
+
+	files := make(map[*pflag.FlagSet]configFile)
+	paths := make(map[*pflag.FlagSet]string)
+	for _, fs := range s.pflagSets() {
+		path := s.configPath(fs)
+		if path == "" {
+			continue
+		}
+
+		cfg, err := next.readConfig(path)
+		if err != nil {
+			errs = append(errs, configError(path, "", err))
+			continue
+		}
+
+		errs = append(errs, s.unknownKeyErrors(path, cfg, fs)...)
+
+		files[fs], paths[fs] = cfg, path
+	}
+
+	pass := make(sourcePass)
+	reloaded := make(map[*flag]*flag)
+	for i, f := range s.flags {
+		if !f.reloadable || f.fs.Lookup(f.name).Changed {
+			continue
+		}
+
+		g, err := f.reloaded(files[f.fs], paths[f.fs], pass)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		next.flags[i], reloaded[f] = g, g
+	}
+
+	for _, f := range s.flags {
+		if g, ok := reloaded[f]; ok {
+			errs = append(errs, g.validate()...)
+		}
+	}
+	errs = append(errs, next.checkConstraints()...)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, f := range s.flags {
+		g, ok := reloaded[f]
+		if !ok {
+			continue
+		}
+
+		dest := reflect.ValueOf(f.dest).Elem()
+		old := dest.Interface()
+		val := reflect.ValueOf(g.dest).Elem().Interface()
+		dest.Set(reflect.ValueOf(g.dest).Elem())
+		f.origin, f.originKey = g.origin, g.originKey
+		f.configErr = nil
+
+		if !reflect.DeepEqual(old, val) {
+			changes = append(changes, Change{Name: f.name, Old: old, New: val})
+		}
+	}
+	s.configs, s.configStates = next.configs, next.configStates
+
+	return changes, nil
+}
+
+// reloaded returns a copy of the flag with a destination of its own, set from
+// the flag's sources, the config file cfg read from path, or its default, in
+// that order of precedence.
+func (f *flag) reloaded(cfg configFile, path string, pass sourcePass) (*flag, error) {
+	fi, ok := lookupType(f.dest)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T", f.dest)
+	}
+
+	g := *f
+	g.dest = reflect.New(reflect.TypeOf(f.dest).Elem()).Interface()
+	g.errs = nil
+
+	err := g.reload(cfg, path, pass)
+	f.envAliasWarned = g.envAliasWarned
+	if err != nil {
+		return nil, err
+	}
+
+	// Define the copy on a set of its own, so that its pflag.Value reads the
+	// new destination when constraints are checked.
+	g.fs = pflag.NewFlagSet(f.name, pflag.ContinueOnError)
+	fi.bind(g.fs, &g)
+
+	return &g, nil
+}
+
+// reload sets the flag's value from its sources, the config file cfg read from
+// path, or its default, in that order of precedence.
+func (f *flag) reload(cfg configFile, path string, pass sourcePass) error {
+	fi, ok := lookupType(f.dest)
+	if !ok {
+		return fmt.Errorf("unsupported type %T", f.dest)
+	}
+
+	dest := reflect.ValueOf(f.dest).Elem()
+
+	// lookupSources records problems in f.errs.
+	sv, ok := f.lookupSources(pass)
+	if len(f.errs) > 0 {
+		return errors.Join(f.errs...)
+	}
+
+	if ok {
+		val, err := fi.parse(f, sv.Value)
+		if err != nil {
+			return f.parseError(sv, err)
+		}
+		dest.Set(reflect.ValueOf(val))
+		f.origin, f.originKey = sv.Origin, sv.Key
+		return nil
+	}
+
+	if val, ok := f.configValue(cfg); ok {
+		str := formatConfigValue(f, val)
+		if err := f.setString(str); err != nil {
+			return f.parseError(SourceValue{Value: str, Origin: OriginFile, Key: path}, err)
+		}
+		f.origin, f.originKey = OriginFile, path
+		return nil
+	}
+
+	if f.defaultValue != nil {
+		dest.Set(reflect.ValueOf(f.defaultValue))
+	} else {
+		dest.Set(reflect.Zero(dest.Type()))
+	}
+	f.origin, f.originKey = OriginDefault, ""
+
+	return nil
+}
+
+type watchOptions struct {
+	signals  []os.Signal
+	interval time.Duration
+	onError  func(error)
+}
+
+// WatchOption is used to customize Watch.
+type WatchOption func(*watchOptions)
+
+// WatchSignals sets the signals that trigger a reload. Defaults to SIGHUP.
+func WatchSignals(sigs ...os.Signal) WatchOption {
+	return func(o *watchOptions) {
+		o.signals = sigs
+	}
+}
+
+// WatchInterval sets how often config files are checked for changes. Defaults
+// to DefaultWatchInterval. Zero disables checking.
+func WatchInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.interval = d
+	}
+}
+
+// OnReloadError sets the function called when a reload fails. By default the
+// error is written to os.Stderr.
+func OnReloadError(fn func(error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = fn
+	}
+}
+
+// Watch calls Reload whenever one of the watched signals is received or a
+// config file's modification time or size changes, until ctx is done.
+func (s *FlagSet) Watch(ctx context.Context, opts ...WatchOption) {
+	o := watchOptions{
+		signals:  []os.Signal{syscall.SIGHUP},
+		interval: DefaultWatchInterval,
+		onError: func(err error) {
+			fmt.Fprintln(os.Stderr, err)
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	reload := func() {
+		if err := s.Reload(); err != nil {
+			o.onError(err)
+		}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	if len(o.signals) > 0 {
+		signal.Notify(sigs, o.signals...)
+		defer signal.Stop(sigs)
+	}
+
+	var tick <-chan time.Time
+	if o.interval > 0 {
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	mu := s.lock()
+	mu.RLock()
+	files := maps.Clone(s.configStates)
+	mu.RUnlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigs:
+			files = s.fileStates()
+			reload()
+		case <-tick:
+			current := s.fileStates()
+			if !reflect.DeepEqual(files, current) {
+				files = current
+				reload()
+			}
+		}
+	}
+}
+
+// fileState is what Watch compares to detect a changed config file.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	missing bool
+}
+
+func (s *FlagSet) fileStates() map[string]fileState {
+	states := make(map[string]fileState)
+	for _, fs := range s.pflagSets() {
+		path := s.configPath(fs)
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			states[path] = fileState{missing: true}
+			continue
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states
+}
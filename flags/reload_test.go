@@ -0,0 +1,254 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadConfig struct {
+	Level   string
+	Workers int
+	Addr    string
+	Tags    []string
+
+	FlagSet
+}
+
+func newReloadConfig(t *testing.T, path string, args ...string) *reloadConfig {
+	t.Helper()
+
+	var c reloadConfig
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlag(fs)
+	c.Add(
+		fs,
+		New(&c.Level, "level", "Log level.", Env("TEST_LEVEL"), Default("info"), Reloadable(), Validate(OneOf("debug", "info"))),
+		New(&c.Workers, "workers", "Workers.", Default(1), Reloadable()),
+		New(&c.Addr, "addr", "Address."),
+		New(&c.Tags, "tags", "Tags.", Reloadable()),
+	)
+
+	require.NoError(t, fs.Parse(append([]string{"--config", path}, args...)))
+	require.NoError(t, c.Check())
+
+	return &c
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "level: info\nworkers: 2\naddr: a:1\n")
+
+	c := newReloadConfig(t, path)
+
+	var got []Change
+	c.Subscribe(func(changes []Change) { got = append(got, changes...) })
+
+	writeFile(t, path, "level: debug\naddr: b:2\ntags: [x, y]\n")
+	require.NoError(t, c.Reload())
+
+	require.Equal(t, "debug", c.Level)
+	require.Equal(t, 1, c.Workers)
+	require.Equal(t, []string{"x", "y"}, c.Tags)
+	require.Equal(t, "a:1", c.Addr, "flags that aren't reloadable keep their value")
+
+	require.Equal(t, []Change{
+		{Name: "level", Old: "info", New: "debug"},
+		{Name: "workers", Old: 2, New: 1},
+		{Name: "tags", Old: []string(nil), New: []string{"x", "y"}},
+	}, got)
+
+	origin, key, _ := c.Origin("workers")
+	require.Equal(t, OriginDefault, origin)
+	require.Empty(t, key)
+
+	// Nothing changed, so the subscribers aren't called.
+	got = nil
+	require.NoError(t, c.Reload())
+	require.Nil(t, got)
+}
+
+func TestReloadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "level: info\nworkers: 2\n")
+
+	c := newReloadConfig(t, path, "--workers", "3")
+
+	t.Setenv("TEST_LEVEL", "debug")
+	writeFile(t, path, "level: info\nworkers: 4\n")
+	require.NoError(t, c.Reload())
+
+	require.Equal(t, "debug", c.Level)
+	require.Equal(t, 3, c.Workers)
+
+	origin, key, _ := c.Origin("level")
+	require.Equal(t, OriginEnv, origin)
+	require.Equal(t, "TEST_LEVEL", key)
+}
+
+func TestReloadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "level: debug\nworkers: 2\n")
+
+	c := newReloadConfig(t, path)
+
+	called := false
+	c.Subscribe(func([]Change) { called = true })
+
+	writeFile(t, path, "level: loud\nworkers: 5\n")
+	require.EqualError(t, c.Reload(), `reloading configuration: invalid value for "level": "loud" is not one of debug, info`)
+	require.Equal(t, "debug", c.Level)
+	require.Equal(t, 2, c.Workers)
+	require.False(t, called)
+
+	writeFile(t, path, "level: debug\nworkers: [\n")
+	require.ErrorContains(t, c.Reload(), "parsing config file")
+	require.Equal(t, 2, c.Workers)
+
+	writeFile(t, path, "level: debug\nworkers: 2\nbogus: true\n")
+	require.ErrorContains(t, c.Reload(), `unknown key "bogus"`)
+
+	origin, key, _ := c.Origin("workers")
+	require.Equal(t, OriginFile, origin)
+	require.Equal(t, path, key)
+}
+
+func TestReloadThenCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "level: info\n")
+
+	c := newReloadConfig(t, path)
+	require.Empty(t, c.Addr)
+
+	writeFile(t, path, "level: debug\naddr: b:2\n")
+	require.NoError(t, c.Reload())
+	require.Equal(t, "debug", c.Level)
+
+	// Flags that aren't reloadable keep their value when checked again.
+	require.NoError(t, c.Check())
+	require.Empty(t, c.Addr)
+	require.Equal(t, "debug", c.Level)
+}
+
+func TestReloadConstraints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "level: info\n")
+
+	c := newReloadConfig(t, path)
+	c.RequiredIf("addr", Equals("level", "debug"))
+
+	// The constraint sees the reloaded value before it is applied.
+	writeFile(t, path, "level: debug\n")
+	require.EqualError(t, c.Reload(), `reloading configuration: "addr" is required when --level is "debug"`)
+	require.Equal(t, "info", c.Level)
+
+	writeFile(t, path, "level: debug\naddr: a:1\n")
+	require.ErrorContains(t, c.Reload(), `"addr" is required`, "addr isn't reloadable")
+}
+
+func TestReloadSatisfies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "tls: false\n")
+
+	var (
+		s    FlagSet
+		tls  bool
+		cert string
+	)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlag(fs)
+	s.Add(
+		fs,
+		New(&tls, "tls", "TLS.", Reloadable()),
+		New(&cert, "tls-cert", "Certificate.", Reloadable()),
+	)
+	s.RequiredIf("tls-cert", Satisfies("tls", "TLS is enabled", func(on bool) bool { return on }))
+
+	require.NoError(t, fs.Parse([]string{"--config", path}))
+	require.NoError(t, s.Check())
+
+	writeFile(t, path, "tls: true\n")
+	require.EqualError(t, s.Reload(), `reloading configuration: "tls-cert" is required when TLS is enabled`)
+	require.False(t, tls)
+
+	writeFile(t, path, "tls: true\ntls-cert: cert.pem\n")
+	require.NoError(t, s.Reload())
+	require.True(t, tls)
+	require.Equal(t, "cert.pem", cert)
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "level: info\n")
+
+	c := newReloadConfig(t, path)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			_ = c.Reload()
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, c.Check())
+		require.Len(t, c.Effective(), 4)
+		c.Read(func() { require.Equal(t, "info", c.Level) })
+	}
+	<-done
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "workers: 2\n")
+
+	c := newReloadConfig(t, path)
+
+	changed := make(chan []Change, 1)
+	c.Subscribe(func(changes []Change) { changed <- changes })
+
+	failed := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Watch(ctx, WatchSignals(), WatchInterval(10*time.Millisecond), OnReloadError(func(err error) { failed <- err }))
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	writeFile(t, path, "workers: 3\nlevel: nope\n")
+	select {
+	case err := <-failed:
+		require.ErrorContains(t, err, `invalid value for "level"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal(errors.New("timed out waiting for the reload to fail"))
+	}
+
+	writeFile(t, path, "workers: 4\n")
+	select {
+	case changes := <-changed:
+		require.Equal(t, []Change{{Name: "workers", Old: 2, New: 4}}, changes)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	c.Read(func() {
+		require.Equal(t, 4, c.Workers)
+	})
+}
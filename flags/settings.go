@@ -0,0 +1,102 @@
+package flags
+
+import (
+	"strings"
+)
+
+// SetOption is used to customize a FlagSet.
+type SetOption func(*FlagSet)
+
+// EnvPrefix sets a prefix, e.g. "MYAPP_", that is prepended to the environment
+// variable of every flag added to the FlagSet afterwards.
+func EnvPrefix(prefix string) SetOption {
+	return func(s *FlagSet) {
+		s.envPrefix = prefix
+	}
+}
+
+// NamePrefix sets a prefix, e.g. "primary-", that is prepended to the name of
+// every flag added to the FlagSet afterwards, including aliases. Methods of the
+// FlagSet that take a flag name, such as Origin and MutuallyExclusive, expect
+// the name without the prefix.
+func NamePrefix(prefix string) SetOption {
+	return func(s *FlagSet) {
+		s.namePrefix = prefix
+	}
+}
+
+// AutoEnv derives an environment variable for every flag added to the FlagSet
+// afterwards that doesn't have one, by upper casing the flag's name and
+// replacing dashes with underscores, so "db-host" becomes "DB_HOST". The name
+// used is the one the flag was created with, before NamePrefix is applied, and
+// the EnvPrefix is prepended as usual.
+func AutoEnv() SetOption {
+	return func(s *FlagSet) {
+		s.autoEnv = true
+	}
+}
+
+// LookupEnv sets the function used to read environment variables, which
+// defaults to os.LookupEnv. It affects flags added to the FlagSet afterwards and
+// is mostly useful in tests, see the flagstest package.
+func LookupEnv(fn func(key string) (string, bool)) SetOption {
+	return func(s *FlagSet) {
+		s.lookupEnvFunc = fn
+	}
+}
+
+// LenientConfig ignores keys in config files that don't correspond to a flag,
+// which are otherwise reported as errors. Prefer KnownConfigKeys for a config
+// file shared by several commands, so that typos are still caught.
+func LenientConfig() SetOption {
+	return func(s *FlagSet) {
+		s.lenientConfig = true
+	}
+}
+
+// KnownConfigKeys sets the function that decides whether a key in a config file
+// corresponds to a flag. By default, a key is known if a flag of that name is
+// defined on the pflag.FlagSet the FlagSet's flags were added to. The root
+// package uses it to accept the flags of every command sharing the file.
+func KnownConfigKeys(known func(key string) bool) SetOption {
+	return func(s *FlagSet) {
+		s.knownKey = known
+	}
+}
+
+// Configure applies the options to the FlagSet. Options only affect flags added
+// after they have been applied.
+func (s *FlagSet) Configure(opts ...SetOption) {
+	for _, opt := range opts {
+		opt(s)
+	}
+}
+
+// applySettings renames the flag and its environment variables according to
+// the FlagSet's settings and sets how they are read.
+func (s *FlagSet) applySettings(f *flag) {
+	f.lookupEnvFunc = s.lookupEnvFunc
+	f.sources = s.sources
+
+	if f.envVar == "" && s.autoEnv {
+		f.envVar = envName(f.name)
+	}
+
+	if f.envVar != "" {
+		f.envVar = s.envPrefix + f.envVar
+	}
+
+	for i := range f.envAliases {
+		f.envAliases[i] = s.envPrefix + f.envAliases[i]
+	}
+
+	f.name = s.namePrefix + f.name
+	for i := range f.aliases {
+		f.aliases[i] = s.namePrefix + f.aliases[i]
+	}
+}
+
+// envName converts a flag name to an environment variable name.
+func envName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
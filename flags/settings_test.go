@@ -0,0 +1,65 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type settingsConfig struct {
+	Host string
+	Port int
+	Pass string
+
+	FlagSet
+}
+
+func newSettingsConfig(fs *pflag.FlagSet, opts ...SetOption) *settingsConfig {
+	var c settingsConfig
+	c.Configure(opts...)
+	c.Add(
+		fs,
+		New(&c.Host, "db-host", "Host"),
+		New(&c.Port, "db-port", "Port", Env("PORT"), Default(5432)),
+		New(&c.Pass, "db-pass", "Password", Env("DB_PASSWORD"), Alias("db-password")),
+	)
+	c.RequiredTogether("db-host", "db-pass")
+	return &c
+}
+
+func TestSettings(t *testing.T) {
+	t.Setenv("PRIMARY_DB_HOST", "db1")
+	t.Setenv("PRIMARY_PORT", "6432")
+	t.Setenv("REPLICA_DB_HOST", "db2")
+	t.Setenv("DB_HOST", "unprefixed")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	primary := newSettingsConfig(fs, NamePrefix("primary-"), EnvPrefix("PRIMARY_"), AutoEnv())
+	replica := newSettingsConfig(fs, NamePrefix("replica-"), EnvPrefix("REPLICA_"), AutoEnv())
+	plain := newSettingsConfig(fs, AutoEnv())
+
+	require.NotNil(t, fs.Lookup("primary-db-host"))
+	require.NotNil(t, fs.Lookup("replica-db-password"))
+	require.Contains(t, fs.Lookup("primary-db-pass").Usage, "PRIMARY_DB_PASSWORD")
+	require.Contains(t, fs.Lookup("primary-db-pass").Usage, "must be specified with --primary-db-host")
+
+	require.NoError(t, fs.Parse([]string{"--primary-db-pass", "a", "--replica-db-password", "b", "--db-pass", "c"}))
+
+	require.NoError(t, primary.Check())
+	require.Equal(t, "db1", primary.Host)
+	require.Equal(t, 6432, primary.Port)
+	require.Equal(t, "a", primary.Pass)
+
+	require.NoError(t, replica.Check())
+	require.Equal(t, "db2", replica.Host)
+	require.Equal(t, 5432, replica.Port)
+	require.Equal(t, "b", replica.Pass)
+
+	require.NoError(t, plain.Check())
+	require.Equal(t, "unprefixed", plain.Host)
+
+	_, key, ok := primary.Origin("db-host")
+	require.True(t, ok)
+	require.Equal(t, "PRIMARY_DB_HOST", key)
+}
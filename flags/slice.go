@@ -0,0 +1,82 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultSeparator is used to split environment variable values for slice and
+// map flags when no Separator option is given.
+const DefaultSeparator = ","
+
+func init() {
+	registerType((*pflag.FlagSet).StringSliceVar, parseSlice(parseString), nonEmpty[[]string])
+	registerType((*pflag.FlagSet).IntSliceVar, parseSlice(strconv.Atoi), nonEmpty[[]int])
+	registerType((*pflag.FlagSet).DurationSliceVar, parseSlice(time.ParseDuration), nonEmpty[[]time.Duration])
+	registerType((*pflag.FlagSet).StringToStringVar, parseStringMap, nonEmpty[map[string]string])
+}
+
+// Separator sets the separator used to split environment variable values for
+// slice and map flags. Defaults to DefaultSeparator.
+func Separator(sep string) Option {
+	return func(o *flag) {
+		o.separator = sep
+	}
+}
+
+func (f *flag) sep() string {
+	if f.separator == "" {
+		return DefaultSeparator
+	}
+	return f.separator
+}
+
+func (f *flag) split(s string) []string {
+	var result []string
+	for _, v := range strings.Split(s, f.sep()) {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+func parseSlice[T any](parse func(string) (T, error)) func(*flag, string) ([]T, error) {
+	return func(f *flag, s string) ([]T, error) {
+		result := []T{}
+		for _, v := range f.split(s) {
+			parsed, err := parse(v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, parsed)
+		}
+		return result, nil
+	}
+}
+
+func parseStringMap(f *flag, s string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, v := range f.split(s) {
+		key, val, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q must be formatted as key=value", v)
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return result, nil
+}
+
+// nonEmpty is a check function that fails when a slice or map has no elements.
+func nonEmpty[T any](name string, val T) error {
+	if reflect.ValueOf(val).Len() == 0 {
+		return fmt.Errorf("required value %q not specified", name)
+	}
+	return nil
+}
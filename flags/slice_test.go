@@ -0,0 +1,51 @@
+package flags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlices(t *testing.T) {
+	t.Setenv("TEST_HOSTS", "a, b,,c")
+	t.Setenv("TEST_PORTS", "1;2;3")
+	t.Setenv("TEST_TIMEOUTS", "1s,2m")
+	t.Setenv("TEST_LABELS", "env=prod, team = core")
+
+	var (
+		hosts    []string
+		ports    []int
+		timeouts []time.Duration
+		labels   map[string]string
+		topics   []string
+	)
+
+	var s FlagSet
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(
+		fs,
+		New(&hosts, "hosts", "Hosts", Env("TEST_HOSTS"), Validate(NotBlank())),
+		New(&ports, "ports", "Ports", Env("TEST_PORTS"), Separator(";"), Validate(Range(1, 2))),
+		New(&timeouts, "timeouts", "Timeouts", Env("TEST_TIMEOUTS")),
+		New(&labels, "labels", "Labels", Env("TEST_LABELS")),
+		New(&topics, "topics", "Topics", Default([]string{}), RequiredNonZero()),
+	)
+
+	require.NoError(t, fs.Parse(nil))
+	require.Equal(t, []string{"a", "b", "c"}, hosts)
+	require.Equal(t, []int{1, 2, 3}, ports)
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, timeouts)
+	require.Equal(t, map[string]string{"env": "prod", "team": "core"}, labels)
+
+	require.EqualError(
+		t,
+		s.Check(),
+		"invalid value for \"ports\": 3 is not between 1 and 2\nrequired value \"topics\" not specified",
+	)
+
+	require.NoError(t, fs.Parse([]string{"--topics", "x,y", "--ports", "1"}))
+	require.Equal(t, []string{"x", "y"}, topics)
+	require.NoError(t, s.Check())
+}
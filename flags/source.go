@@ -0,0 +1,347 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source provides values for flags from somewhere other than the command line,
+// such as the environment or a secret store. The values from sources set with
+// Sources are looked up by Load, which Check calls, and again by Reload, so
+// that merely defining the flags, e.g. to print the usage text, never waits on
+// them. Without Sources, only the environment is read, when the flags are
+// added.
+type Source interface {
+	// Lookup returns the value for the flag identified by key. ok is false if
+	// the source doesn't have a value for it.
+	Lookup(key SourceKey) (val SourceValue, ok bool, err error)
+}
+
+// SourceKey identifies a flag to a Source.
+type SourceKey struct {
+	// Name is the flag's name, including any prefix set with NamePrefix.
+	Name string
+	// Env is the flag's environment variable, if any.
+	Env string
+
+	flag *flag
+}
+
+// SourceValue is a value found by a Source.
+type SourceValue struct {
+	// Value is parsed the same way as an environment variable.
+	Value string
+	// Origin and Key describe where the value came from, as in EffectiveValue.
+	// If Origin is OriginDefault, OriginSource is used instead.
+	Origin Origin
+	Key    string
+}
+
+// Sources sets the sources that are consulted, in order, for the value of
+// every flag added to the FlagSet afterwards. The first source with a value
+// wins. Values from a config file given with the flag added by AddConfigFlag
+// are only used if none of the sources has one. Defaults to EnvSource alone.
+func Sources(sources ...Source) SetOption {
+	return func(s *FlagSet) {
+		s.sources = sources
+	}
+}
+
+// snapshotter is implemented by sources that read all of their values at
+// once. snapshot returns a Source that answers the lookups of a single pass over
+// the flags from one read.
+type snapshotter interface {
+	snapshot() Source
+}
+
+// sourcePass holds the snapshots of the sources consulted during one pass over
+// the flags, by Load or Reload, so that e.g. a FileSource is read once per
+// pass rather than once per flag.
+type sourcePass map[Source]Source
+
+func (p sourcePass) source(src Source) Source {
+	snap, ok := src.(snapshotter)
+	if !ok || p == nil {
+		return src
+	}
+
+	if cached, ok := p[src]; ok {
+		return cached
+	}
+	p[src] = snap.snapshot()
+	return p[src]
+}
+
+// lookupSources returns the first value for the flag found by its sources.
+// Problems are recorded in f.errs.
+func (f *flag) lookupSources(pass sourcePass) (SourceValue, bool) {
+	sources := f.sources
+	if sources == nil {
+		sources = []Source{EnvSource()}
+	}
+
+	key := SourceKey{Name: f.name, Env: f.envVar, flag: f}
+	for _, src := range sources {
+		val, ok, err := pass.source(src).Lookup(key)
+		if err != nil {
+			f.errs = append(f.errs, f.sourceError(ReasonSource, OriginSource, "", "", fmt.Errorf("looking up %q: %w", f.name, err)))
+			continue
+		}
+		if ok {
+			if val.Origin == OriginDefault {
+				val.Origin = OriginSource
+			}
+			return val, true
+		}
+	}
+
+	return SourceValue{}, false
+}
+
+// lookupOnAdd returns the flag's value from the environment when it is added,
+// unless Sources were set, in which case they are only looked up by Load.
+func (f *flag) lookupOnAdd() (SourceValue, bool) {
+	if f.sources != nil {
+		return SourceValue{}, false
+	}
+	return f.lookupSources(nil)
+}
+
+// loadSources sets the flag's value from the first of its sources that has
+// one. Problems are recorded in f.errs.
+func (f *flag) loadSources(pass sourcePass) {
+	sv, ok := f.lookupSources(pass)
+	if !ok {
+		return
+	}
+
+	if err := f.setString(sv.Value); err != nil {
+		f.errs = append(f.errs, f.parseError(sv, err))
+		return
+	}
+	f.origin, f.originKey = sv.Origin, sv.Key
+}
+
+// parseError describes a failure to parse val. Parse errors usually contain
+// the value, so they are replaced for secret flags.
+func (f *flag) parseError(val SourceValue, err error) error {
+	if f.secret {
+		err = errors.New("invalid value")
+	}
+
+	if val.Origin == OriginEnv {
+		err = fmt.Errorf("parsing environment variable %s for %q: %w", val.Key, f.name, err)
+	} else {
+		err = fmt.Errorf("parsing value from %s for %q: %w", EffectiveValue{Origin: val.Origin, Key: val.Key}.Describe(), f.name, err)
+	}
+
+	return f.sourceError(ReasonParse, val.Origin, val.Key, val.Value, err)
+}
+
+// EnvSource returns a Source that reads the flag's environment variable,
+// falling back to its deprecated aliases and, for secret flags, to the file
+// named by the variable with a "_FILE" suffix.
+func EnvSource() Source {
+	return envSource{}
+}
+
+type envSource struct{}
+
+func (envSource) Lookup(key SourceKey) (SourceValue, bool, error) {
+	// Keys made outside the package don't identify a flag, so only the
+	// variable itself can be read.
+	if key.flag == nil {
+		if key.Env == "" {
+			return SourceValue{}, false, nil
+		}
+		str, ok := os.LookupEnv(key.Env)
+		return SourceValue{Value: str, Origin: OriginEnv, Key: key.Env}, ok, nil
+	}
+
+	str, envKey, ok := key.flag.lookupEnv()
+	return SourceValue{Value: str, Origin: OriginEnv, Key: envKey}, ok, nil
+}
+
+// FileSource returns a Source that reads values from a YAML, JSON or TOML
+// file, in the same format as the file given with the flag added by
+// AddConfigFlag. A missing file has no values. The file is read once by Load
+// and again by each Reload, so changes are seen by Reload.
+func FileSource(path string) Source {
+	return fileSource(path)
+}
+
+type fileSource string
+
+func (s fileSource) Lookup(key SourceKey) (SourceValue, bool, error) {
+	return s.snapshot().Lookup(key)
+}
+
+func (s fileSource) snapshot() Source {
+	return &fileSnapshot{path: string(s)}
+}
+
+// fileSnapshot is a FileSource's file, read on the first lookup.
+type fileSnapshot struct {
+	path string
+	read bool
+	cfg  configFile
+	err  error
+}
+
+func (s *fileSnapshot) Lookup(key SourceKey) (SourceValue, bool, error) {
+	if key.flag == nil {
+		key.flag = &flag{}
+	}
+
+	if !s.read {
+		s.read = true
+		s.cfg, s.err = parseConfigFile(s.path)
+		if errors.Is(s.err, fs.ErrNotExist) {
+			s.cfg, s.err = nil, nil
+		}
+	}
+	if s.err != nil {
+		return SourceValue{}, false, s.err
+	}
+
+	val, ok := s.cfg.lookup(key.Name)
+	if !ok {
+		return SourceValue{}, false, nil
+	}
+
+	return SourceValue{
+		Value:  formatConfigValue(key.flag, val),
+		Origin: OriginFile,
+		Key:    s.path,
+	}, true, nil
+}
+
+// DirSource returns a Source that reads each flag's value from the file in dir
+// named after the flag, e.g. dir/db-host. Trailing newlines are removed.
+func DirSource(dir string) Source {
+	return dirSource(dir)
+}
+
+type dirSource string
+
+func (s dirSource) Lookup(key SourceKey) (SourceValue, bool, error) {
+	return readValueFile(filepath.Join(string(s), key.Name))
+}
+
+// SecretsDirSource returns a Source that reads each flag's value from the file
+// in dir named after the flag's environment variable, e.g.
+// /etc/secrets/DB_PASSWORD, as secrets are mounted by Kubernetes and Docker
+// Swarm. Flags without an environment variable are skipped. Trailing newlines
+// are removed. Its position in Sources relative to EnvSource decides whether
+// mounted secrets or environment variables take precedence.
+func SecretsDirSource(dir string) Source {
+	return secretsDirSource(dir)
+}
+
+type secretsDirSource string
+
+func (s secretsDirSource) Lookup(key SourceKey) (SourceValue, bool, error) {
+	if key.Env == "" {
+		return SourceValue{}, false, nil
+	}
+	return readValueFile(filepath.Join(string(s), key.Env))
+}
+
+// readValueFile reads a value from the file at path. A missing file has no
+// value.
+func readValueFile(path string) (SourceValue, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return SourceValue{}, false, nil
+	}
+	if err != nil {
+		return SourceValue{}, false, err
+	}
+
+	return SourceValue{
+		Value:  strings.TrimRight(string(data), "\r\n"),
+		Origin: OriginFile,
+		Key:    path,
+	}, true, nil
+}
+
+// HTTPOption is used to customize an HTTPSource.
+type HTTPOption func(*httpSource)
+
+// HTTPClient sets the client used by an HTTPSource. Defaults to a client with
+// a 10 second timeout.
+func HTTPClient(c *http.Client) HTTPOption {
+	return func(s *httpSource) {
+		s.client = c
+	}
+}
+
+// HTTPHeader adds a header, such as an authentication token, to the requests
+// made by an HTTPSource.
+func HTTPHeader(key, value string) HTTPOption {
+	return func(s *httpSource) {
+		s.header.Add(key, value)
+	}
+}
+
+// HTTPSource returns a Source that reads values from a key/value HTTP endpoint.
+// The flag's name, escaped, replaces "{key}" in urlTemplate, e.g.
+// "http://127.0.0.1:8500/v1/kv/myapp/{key}?raw" for Consul. A 200 response's
+// body is the value and a 404 response means there is no value. Any other
+// response is an error.
+func HTTPSource(urlTemplate string, opts ...HTTPOption) Source {
+	s := &httpSource{
+		template: urlTemplate,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		header:   make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type httpSource struct {
+	template string
+	client   *http.Client
+	header   http.Header
+}
+
+func (s *httpSource) Lookup(key SourceKey) (SourceValue, bool, error) {
+	u := strings.ReplaceAll(s.template, "{key}", url.PathEscape(key.Name))
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return SourceValue{}, false, err
+	}
+	req.Header = s.header.Clone()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return SourceValue{}, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return SourceValue{}, false, nil
+	default:
+		return SourceValue{}, false, fmt.Errorf("GET %s: unexpected status %s", u, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SourceValue{}, false, fmt.Errorf("GET %s: %w", u, err)
+	}
+
+	return SourceValue{Value: string(data), Origin: OriginSource, Key: u}, true, nil
+}
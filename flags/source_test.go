@@ -0,0 +1,232 @@
+package flags
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	t.Setenv("TEST_SRC_HOST", "env-host")
+	t.Setenv("TEST_SRC_USER", "env-user")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db-pass"), []byte("dir-pass\n"), 0600))
+
+	file := filepath.Join(t.TempDir(), "source.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("db:\n  user: file-user\n  port: 6432\n"), 0600))
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, "secret-token", r.Header.Get("X-Token"))
+		switch r.URL.Path {
+		case "/kv/db-host":
+			w.Write([]byte("kv-host"))
+		case "/kv/db-name":
+			w.Write([]byte("kv-name"))
+		case "/kv/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("db-name: config-name\ndb-timeout: 5\n"), 0600))
+
+	var (
+		s       FlagSet
+		host    string
+		user    string
+		pass    string
+		port    int
+		name    string
+		timeout int
+		broken  string
+	)
+
+	s.Configure(Sources(
+		DirSource(dir),
+		EnvSource(),
+		FileSource(file),
+		HTTPSource(srv.URL+"/kv/{key}", HTTPHeader("X-Token", "secret-token")),
+	))
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlag(fs)
+	s.Add(
+		fs,
+		New(&host, "db-host", "Host", Env("TEST_SRC_HOST")),
+		New(&user, "db-user", "User"),
+		New(&pass, "db-pass", "Password", Env("TEST_SRC_PASS")),
+		New(&port, "db-port", "Port", Default(5432)),
+		New(&name, "db-name", "Name"),
+		New(&timeout, "db-timeout", "Timeout"),
+		New(&broken, "broken", "Broken"),
+	)
+
+	require.NoError(t, fs.Parse([]string{"--config", config}))
+	require.Zero(t, requests.Load(), "sources are only looked up by Load")
+
+	err := s.Check()
+	require.Error(t, err)
+
+	verrs := ValidationErrors(err)
+	require.Len(t, verrs, 1)
+	require.Equal(t, "broken", verrs[0].Name)
+	require.Equal(t, ReasonSource, verrs[0].Reason)
+	require.ErrorContains(t, verrs[0], "unexpected status 500 Internal Server Error")
+
+	require.Equal(t, "env-host", host)
+	require.Equal(t, "file-user", user)
+	require.Equal(t, "dir-pass", pass)
+	require.Equal(t, 6432, port)
+	require.Equal(t, "kv-name", name)
+	require.Equal(t, 5, timeout)
+
+	tests := []struct {
+		name   string
+		origin Origin
+		key    string
+	}{
+		{"db-host", OriginEnv, "TEST_SRC_HOST"},
+		{"db-user", OriginFile, file},
+		{"db-pass", OriginFile, filepath.Join(dir, "db-pass")},
+		{"db-name", OriginSource, srv.URL + "/kv/db-name"},
+		{"db-timeout", OriginFile, config},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origin, key, ok := s.Origin(tt.name)
+			require.True(t, ok)
+			require.Equal(t, tt.origin, origin)
+			require.Equal(t, tt.key, key)
+		})
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "port"), []byte("abc"), 0600))
+
+	var (
+		s    FlagSet
+		port int
+	)
+
+	s.Configure(Sources(DirSource(dir)))
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(fs, New(&port, "port", "Port"))
+
+	path := filepath.Join(dir, "port")
+	require.EqualError(t, s.Check(), `parsing value from file `+path+` for "port": strconv.ParseInt: parsing "abc": invalid syntax`)
+}
+
+func TestEnvSourceLookupEnv(t *testing.T) {
+	var (
+		s    FlagSet
+		host string
+	)
+
+	s.Configure(
+		LookupEnv(func(key string) (string, bool) {
+			return map[string]string{"TEST_HOST": "lookup-host"}[key], key == "TEST_HOST"
+		}),
+		Sources(EnvSource()),
+	)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	s.Add(fs, New(&host, "host", "Host", Env("TEST_HOST")))
+
+	require.NoError(t, fs.Parse(nil))
+	require.NoError(t, s.Check())
+	require.Equal(t, "lookup-host", host)
+}
+
+func TestFileSourcePass(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "source.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("host: a\n"), 0600))
+
+	pass := make(sourcePass)
+	src := pass.source(FileSource(path))
+	require.Same(t, src, pass.source(FileSource(path)))
+
+	val, ok, err := src.Lookup(SourceKey{Name: "host"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "a", val.Value)
+
+	// The file is read once per pass.
+	require.NoError(t, os.WriteFile(path, []byte("host: b\n"), 0600))
+	val, _, _ = src.Lookup(SourceKey{Name: "host"})
+	require.Equal(t, "a", val.Value)
+
+	val, _, _ = make(sourcePass).source(FileSource(path)).Lookup(SourceKey{Name: "host"})
+	require.Equal(t, "b", val.Value)
+}
+
+func TestSecretsDirSource(t *testing.T) {
+	dir := t.TempDir()
+	for name, val := range map[string]string{
+		"TEST_SECRET_PASS":    "file-pass\n",
+		"TEST_SECRET_PORT":    "6432",
+		"TEST_SECRET_HOSTS":   "a,b",
+		"TEST_SECRET_TIMEOUT": "5s",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(val), 0600))
+	}
+
+	t.Setenv("TEST_SECRET_PASS", "env-pass")
+
+	tests := []struct {
+		name    string
+		sources []Source
+		pass    string
+	}{
+		{"secrets first", []Source{SecretsDirSource(dir), EnvSource()}, "file-pass"},
+		{"env first", []Source{EnvSource(), SecretsDirSource(dir)}, "env-pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				s       FlagSet
+				pass    string
+				port    int
+				hosts   []string
+				timeout time.Duration
+				user    string
+			)
+
+			s.Configure(Sources(tt.sources...))
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			s.Add(
+				fs,
+				New(&pass, "db-pass", "Password", Env("TEST_SECRET_PASS"), Secret()),
+				New(&port, "db-port", "Port", Env("TEST_SECRET_PORT")),
+				New(&hosts, "hosts", "Hosts", Env("TEST_SECRET_HOSTS")),
+				New(&timeout, "timeout", "Timeout", Env("TEST_SECRET_TIMEOUT")),
+				New(&user, "db-user", "User", Env("TEST_SECRET_USER"), Default("app")),
+			)
+			require.NoError(t, s.Check())
+
+			require.Equal(t, tt.pass, pass)
+			require.Equal(t, 6432, port)
+			require.Equal(t, []string{"a", "b"}, hosts)
+			require.Equal(t, 5*time.Second, timeout)
+			require.Equal(t, "app", user)
+
+			origin, key, _ := s.Origin("db-port")
+			require.Equal(t, OriginFile, origin)
+			require.Equal(t, filepath.Join(dir, "TEST_SECRET_PORT"), key)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package flags
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	pflagValueType      = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// dynamicType describes destinations of types that haven't been registered
+// but implement pflag.Value or encoding.TextUnmarshaler. Values from the
+// command line, environment variables and config files are all parsed with
+// Set or UnmarshalText.
+func dynamicType(t reflect.Type) (flagInfo, bool) {
+	if t == nil || t.Kind() != reflect.Pointer {
+		return flagInfo{}, false
+	}
+
+	var newValue func(p reflect.Value) pflag.Value
+	switch {
+	case t.Implements(pflagValueType):
+		newValue = func(p reflect.Value) pflag.Value { return p.Interface().(pflag.Value) }
+	case t.Implements(textUnmarshalerType):
+		newValue = func(p reflect.Value) pflag.Value { return textValue{p: p} }
+	default:
+		return flagInfo{}, false
+	}
+
+	elem := t.Elem()
+
+	parse := func(_ *flag, s string) (any, error) {
+		p := reflect.New(elem)
+		if err := newValue(p).Set(s); err != nil {
+			return nil, err
+		}
+		return p.Elem().Interface(), nil
+	}
+
+	return flagInfo{
+		add: func(fs *pflag.FlagSet, f *flag) {
+			dest := reflect.ValueOf(f.dest)
+
+			def := reflect.Zero(elem)
+			if f.defaultValue != nil {
+				def = reflect.ValueOf(f.defaultValue)
+				if def.Type() != elem {
+					panic(fmt.Sprintf("%s is %s, but the default value is %s", f.name, withArticle(elem), withArticle(reflect.TypeOf(f.defaultValue))))
+				}
+			}
+			dest.Elem().Set(def)
+
+			if sv, ok := f.lookupOnAdd(); ok {
+				val, err := parse(f, sv.Value)
+				if err != nil {
+					f.errs = append(f.errs, f.parseError(sv, err))
+				} else {
+					dest.Elem().Set(reflect.ValueOf(val))
+					f.origin, f.originKey = sv.Origin, sv.Key
+				}
+			}
+
+			fs.Var(newValue(dest), f.name, f.Usage())
+
+			for _, alias := range f.aliases {
+				p := reflect.New(elem)
+				p.Elem().Set(dest.Elem())
+				fs.Var(newValue(p), alias, f.Usage())
+				fs.MarkDeprecated(alias, fmt.Sprintf("use --%s instead", f.name))
+				f.aliasDests = append(f.aliasDests, p.Interface())
+			}
+
+			// As for registered types, show the default rather than a value
+			// that came from the environment.
+			p := reflect.New(elem)
+			p.Elem().Set(def)
+			defStr := newValue(p).String()
+			if f.secret && !def.IsZero() {
+				defStr = redacted
+			}
+			fs.Lookup(f.name).DefValue = defStr
+		},
+		parse: parse,
+		check: func(f *flag) error {
+			if reflect.ValueOf(f.dest).Elem().IsZero() {
+				return fmt.Errorf("required value %q not specified", f.name)
+			}
+			return nil
+		},
+		bind: func(fs *pflag.FlagSet, f *flag) {
+			fs.Var(newValue(reflect.ValueOf(f.dest)), f.name, f.Usage())
+		},
+	}, true
+}
+
+// textValue adapts a pointer to a type implementing encoding.TextUnmarshaler
+// to pflag.Value. The value is formatted with MarshalText if it's implemented.
+type textValue struct {
+	p reflect.Value
+}
+
+func (v textValue) Set(s string) error {
+	return v.p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
+func (v textValue) String() string {
+	if !v.p.IsValid() || v.p.IsNil() {
+		return ""
+	}
+
+	if m, ok := v.p.Interface().(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprint(v.p.Elem().Interface())
+}
+
+func (v textValue) Type() string {
+	if name := v.p.Type().Elem().Name(); name != "" {
+		return strings.ToLower(name)
+	}
+	return "value"
+}
@@ -0,0 +1,102 @@
+package flags
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+// color implements pflag.Value.
+type color string
+
+func (c *color) Set(s string) error {
+	switch s {
+	case "red", "green", "blue":
+		*c = color(s)
+		return nil
+	}
+	return errors.New("not a primary color")
+}
+
+func (c *color) String() string { return string(*c) }
+
+func (c *color) Type() string { return "color" }
+
+func TestTextUnmarshaler(t *testing.T) {
+	t.Setenv("TEST_LEVEL", "warn")
+
+	var level slog.Level
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var s FlagSet
+	s.Add(fs, New(&level, "level", "Log level.", Env("TEST_LEVEL"), Default(slog.LevelInfo)))
+
+	require.Equal(t, slog.LevelWarn, level)
+	require.Equal(t, "INFO", fs.Lookup("level").DefValue)
+	require.Contains(t, fs.FlagUsages(), "--level level")
+
+	origin, key, _ := s.Origin("level")
+	require.Equal(t, OriginEnv, origin)
+	require.Equal(t, "TEST_LEVEL", key)
+
+	require.NoError(t, fs.Parse([]string{"--level", "debug"}))
+	require.Equal(t, slog.LevelDebug, level)
+	require.Error(t, fs.Parse([]string{"--level", "loud"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, s.PrintEnv(&buf))
+	require.Contains(t, buf.String(), "TEST_LEVEL=INFO\n")
+}
+
+func TestPflagValue(t *testing.T) {
+	t.Setenv("TEST_COLOR", "purple")
+
+	var c color
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var s FlagSet
+	s.Add(fs, New(&c, "color", "Color.", Env("TEST_COLOR"), RequiredNonZero()))
+
+	err := s.Check()
+	require.ErrorContains(t, err, `parsing environment variable TEST_COLOR for "color": not a primary color`)
+	require.ErrorContains(t, err, `required value "color" not specified`)
+
+	require.NoError(t, fs.Parse([]string{"--color", "red"}))
+	require.Equal(t, color("red"), c)
+}
+
+func TestTextUnmarshalerConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("level: error\ncolor: blue\n"), 0o600))
+
+	var level slog.Level
+	var c color
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlag(fs)
+	var s FlagSet
+	s.Add(fs,
+		New(&level, "level", "Log level."),
+		New(&c, "color", "Color."),
+	)
+
+	require.NoError(t, fs.Parse([]string{"--config", path}))
+	require.NoError(t, s.Check())
+	require.Equal(t, slog.LevelError, level)
+	require.Equal(t, color("blue"), c)
+}
+
+func TestBindTextUnmarshaler(t *testing.T) {
+	var cfg struct {
+		Level slog.Level `flag:"level" default:"warn"`
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	_, err := Bind(fs, &cfg)
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelWarn, cfg.Level)
+	require.Contains(t, fs.FlagUsages(), "(default WARN)")
+}
@@ -4,43 +4,97 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/jasonhancock/go-env"
 	"github.com/spf13/pflag"
 )
 
-func init() {
-	registerType("*time.Duration", durationAdd, durationCheck)
+// DefaultTimeLayouts are the layouts time.Time flags accept when no TimeLayouts
+// option is given.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	time.DateOnly,
 }
 
-func durationAdd(fs *pflag.FlagSet, f *flag) {
-	var defaultVal time.Duration
-	if f.defaultValue != nil {
-		var ok bool
-		defaultVal, ok = f.defaultValue.(time.Duration)
-		if !ok {
-			panic(fmt.Sprintf("%s is a time.Duration, but the default value is not a time.Duration", f.name))
-		}
+func init() {
+	RegisterType(Type[time.Duration]{
+		Add:   (*pflag.FlagSet).DurationVar,
+		Parse: time.ParseDuration,
+		Check: nonZero[time.Duration],
+	})
 
-		if f.envVar != "" {
-			defaultVal = env.Duration(f.envVar, defaultVal)
+	registerFlagType(timeVar, parseTime, func(name string, val time.Time) error {
+		if val.IsZero() {
+			return fmt.Errorf("required value %q not specified", name)
 		}
-	} else {
-		if f.envVar != "" {
-			defaultVal = env.Duration(f.envVar, 0)
+		return nil
+	})
+
+	registerValue("location", ignoreFlag(parseLocation), formatLocation, nil, nonZero[*time.Location])
+}
+
+// TimeLayouts sets the layouts, as understood by time.Parse, that a time.Time
+// flag accepts. They are tried in order and the first is used to format the
+// value. Defaults to DefaultTimeLayouts.
+func TimeLayouts(layouts ...string) Option {
+	return func(o *flag) {
+		o.layouts = layouts
+	}
+}
+
+func (f *flag) timeLayouts() []string {
+	if len(f.layouts) == 0 {
+		return DefaultTimeLayouts
+	}
+	return f.layouts
+}
+
+func timeVar(f *flag) varFunc[time.Time] {
+	return func(fs *pflag.FlagSet, p *time.Time, name string, val time.Time, usage string) {
+		*p = val
+		fs.Var(&value[time.Time]{
+			p:      p,
+			typ:    "time",
+			parse:  func(s string) (time.Time, error) { return parseTime(f, s) },
+			format: func(t time.Time) string { return formatTime(f, t) },
+		}, name, usage)
+	}
+}
+
+// parseTime parses s with each of the flag's layouts in turn. Times without a
+// time zone are in UTC. An empty string results in the zero time.
+func parseTime(f *flag, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range f.timeLayouts() {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
 		}
 	}
 
-	fs.DurationVar(f.dest.(*time.Duration), f.name, defaultVal, f.Usage())
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a time in the format %q", s, f.timeLayouts()[0])
 }
 
-func durationCheck(f *flag) error {
-	val, ok := f.dest.(*time.Duration)
-	if !ok {
-		return fmt.Errorf("%q not a time.Duration", f.name)
+func formatTime(f *flag, t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
-	if *val == 0 {
-		return fmt.Errorf("required value %q not specified", f.name)
+	return t.Format(f.timeLayouts()[0])
+}
+
+// parseLocation loads the named time zone, e.g. "America/New_York". An empty
+// string results in a nil *time.Location.
+func parseLocation(s string) (*time.Location, error) {
+	if s == "" {
+		return nil, nil
 	}
+	return time.LoadLocation(s)
+}
 
-	return nil
+func formatLocation(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.String()
 }
@@ -0,0 +1,145 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// Type describes how flags whose destination is a *T are defined, how values
+// for them are parsed from environment variables and how they are checked when
+// the flag is created with RequiredNonZero.
+type Type[T any] struct {
+	// Add defines the flag on fs. value is the resolved default, taking into
+	// account both the flag's default value and its environment variable. The
+	// pflag.FlagSet methods have a compatible signature, so for types pflag
+	// already knows about this can be something like (*pflag.FlagSet).IntVar.
+	Add func(fs *pflag.FlagSet, p *T, name string, value T, usage string)
+
+	// Parse converts the value of an environment variable into a T.
+	Parse func(s string) (T, error)
+
+	// Check is called for flags created with RequiredNonZero. It should return
+	// an error if val should be considered missing. If nil, such flags always
+	// pass the check.
+	Check func(name string, val T) error
+}
+
+// RegisterType registers T so that flags with a destination of type *T can be
+// passed to FlagSet.Add. Registering a type a second time replaces the previous
+// registration. RegisterType is not safe to call concurrently with FlagSet.Add
+// and is intended to be called from an init function.
+func RegisterType[T any](t Type[T]) {
+	if t.Add == nil {
+		panic("flags: RegisterType requires an Add function")
+	}
+	if t.Parse == nil {
+		panic("flags: RegisterType requires a Parse function")
+	}
+
+	registerType(t.Add, ignoreFlag(t.Parse), t.Check)
+}
+
+// varFunc defines a flag on fs, like the pflag.FlagSet methods such as IntVar.
+type varFunc[T any] func(fs *pflag.FlagSet, p *T, name string, value T, usage string)
+
+// registerType registers T using a parse function that has access to the flag
+// being parsed, for types whose parsing depends on the flag's options.
+func registerType[T any](
+	add func(fs *pflag.FlagSet, p *T, name string, value T, usage string),
+	parse func(f *flag, s string) (T, error),
+	check func(name string, val T) error,
+) {
+	registerFlagType(func(*flag) varFunc[T] { return add }, parse, check)
+}
+
+// registerFlagType registers T using a function that returns the varFunc for a
+// flag, for types whose command line parsing depends on the flag's options.
+func registerFlagType[T any](
+	varFor func(f *flag) varFunc[T],
+	parse func(f *flag, s string) (T, error),
+	check func(name string, val T) error,
+) {
+	flagTypes[reflect.TypeOf((*T)(nil))] = flagInfo{
+		add: func(fs *pflag.FlagSet, f *flag) {
+			add := varFor(f)
+
+			var val T
+			if f.defaultValue != nil {
+				var ok bool
+				val, ok = f.defaultValue.(T)
+				if !ok {
+					panic(fmt.Sprintf("%s is %s, but the default value is %s", f.name, withArticle(reflect.TypeOf(val)), withArticle(reflect.TypeOf(f.defaultValue))))
+				}
+			}
+
+			def := val
+
+			if sv, ok := f.lookupOnAdd(); ok {
+				v, err := parse(f, sv.Value)
+				if err != nil {
+					f.errs = append(f.errs, f.parseError(sv, err))
+				} else {
+					val = v
+					f.origin, f.originKey = sv.Origin, sv.Key
+				}
+			}
+
+			add(fs, f.dest.(*T), f.name, val, f.Usage())
+
+			for _, alias := range f.aliases {
+				p := new(T)
+				add(fs, p, alias, val, f.Usage())
+				fs.MarkDeprecated(alias, fmt.Sprintf("use --%s instead", f.name))
+				f.aliasDests = append(f.aliasDests, p)
+			}
+
+			// pflag shows the value passed to add as the default in the usage
+			// text, which may have come from the environment.
+			fs.Lookup(f.name).DefValue = defValue(add, f, def)
+		},
+		parse: func(f *flag, s string) (any, error) {
+			return parse(f, s)
+		},
+		check: func(f *flag) error {
+			if check == nil {
+				return nil
+			}
+			return check(f.name, *f.dest.(*T))
+		},
+		bind: func(fs *pflag.FlagSet, f *flag) {
+			p := f.dest.(*T)
+			varFor(f)(fs, p, f.name, *p, f.Usage())
+		},
+	}
+}
+
+// nonZero is a check function that fails when val is the zero value for T.
+func nonZero[T comparable](name string, val T) error {
+	var zero T
+	if val == zero {
+		return fmt.Errorf("required value %q not specified", name)
+	}
+	return nil
+}
+
+// defValue formats def the way the flag's pflag type does. Non-zero defaults
+// of redacted flags are masked.
+func defValue[T any](add varFunc[T], f *flag, def T) string {
+	format := func(val T) string {
+		tmp := pflag.NewFlagSet(f.name, pflag.ContinueOnError)
+		add(tmp, new(T), f.name, val, "")
+		return displayValue(tmp.Lookup(f.name).Value)
+	}
+
+	str := format(def)
+	if f.secret {
+		var zero T
+		if str != format(zero) {
+			return redacted
+		}
+	}
+
+	return str
+}
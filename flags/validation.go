@@ -0,0 +1,152 @@
+package flags
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Reason classifies a ValidationError.
+type Reason string
+
+const (
+	// ReasonMissing means a required flag wasn't given a value.
+	ReasonMissing Reason = "missing"
+	// ReasonParse means a value from an environment variable or config file
+	// couldn't be parsed, or couldn't be read from a file.
+	ReasonParse Reason = "parse"
+	// ReasonInvalid means a value was rejected by one of the flag's validators.
+	ReasonInvalid Reason = "invalid"
+	// ReasonConstraint means a constraint between flags was broken.
+	ReasonConstraint Reason = "constraint"
+	// ReasonConflict means a flag and one of its deprecated aliases were given
+	// different values.
+	ReasonConflict Reason = "conflict"
+	// ReasonConfig means a config file couldn't be read or contains a key that
+	// doesn't correspond to a flag.
+	ReasonConfig Reason = "config"
+	// ReasonSource means a Source failed to look up a value.
+	ReasonSource Reason = "source"
+)
+
+// ValidationError describes a problem found by Check or Reload.
+type ValidationError struct {
+	// Name is the name of the flag. For constraint errors it is the first of
+	// Flags, and for config errors it is empty.
+	Name string
+	// Flags are the names of all the flags involved in a constraint.
+	Flags []string
+	// Env is the flag's environment variable, if any.
+	Env string
+	// Origin and Key describe where the offending value came from, as in
+	// EffectiveValue.
+	Origin Origin
+	Key    string
+	// Value is the offending value, redacted for flags created with the Secret
+	// option. For unknown config file keys it is the key.
+	Value  string
+	Reason Reason
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Describe returns a human readable description of where the offending value
+// came from, e.g. "env DB_HOST".
+func (e *ValidationError) Describe() string {
+	return EffectiveValue{Origin: e.Origin, Key: e.Key}.Describe()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON encodes the error as an object, with the message under "error".
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name   string   `json:"name,omitempty"`
+		Flags  []string `json:"flags,omitempty"`
+		Env    string   `json:"env,omitempty"`
+		Origin string   `json:"origin"`
+		Key    string   `json:"key,omitempty"`
+		Value  string   `json:"value,omitempty"`
+		Reason Reason   `json:"reason"`
+		Error  string   `json:"error"`
+	}{
+		Name:   e.Name,
+		Flags:  e.Flags,
+		Env:    e.Env,
+		Origin: e.Origin.String(),
+		Key:    e.Key,
+		Value:  e.Value,
+		Reason: e.Reason,
+		Error:  e.Err.Error(),
+	})
+}
+
+// ValidationErrors returns every *ValidationError in err's tree, in order.
+// Errors returned by Check and Reload are made up entirely of them.
+func ValidationErrors(err error) []*ValidationError {
+	var errs []*ValidationError
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *ValidationError:
+			errs = append(errs, e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+	return errs
+}
+
+// validationError returns a ValidationError for the flag describing its
+// current value.
+func (f *flag) validationError(reason Reason, err error) *ValidationError {
+	v := f.effective()
+	return &ValidationError{
+		Name:   f.name,
+		Env:    f.envVar,
+		Origin: v.Origin,
+		Key:    v.Key,
+		Value:  v.Value,
+		Reason: reason,
+		Err:    err,
+	}
+}
+
+// sourceError returns a ValidationError for a value read from the environment
+// or a config file.
+func (f *flag) sourceError(reason Reason, origin Origin, key, value string, err error) *ValidationError {
+	if f.secret && value != "" {
+		value = redacted
+	}
+	return &ValidationError{
+		Name:   f.name,
+		Env:    f.envVar,
+		Origin: origin,
+		Key:    key,
+		Value:  value,
+		Reason: reason,
+		Err:    err,
+	}
+}
+
+// configError returns a ValidationError for a problem with the config file at
+// path.
+func configError(path, value string, err error) *ValidationError {
+	return &ValidationError{
+		Origin: OriginFile,
+		Key:    path,
+		Value:  value,
+		Reason: ReasonConfig,
+		Err:    err,
+	}
+}
@@ -0,0 +1,77 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("port: abc\nbogus: 1\n"), 0o600))
+
+	t.Setenv("TEST_TOKEN", "hunter2")
+	t.Setenv("TEST_WORKERS", "many")
+
+	var host, token, mode, cert, key string
+	var port, workers int
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddConfigFlag(fs)
+	var s FlagSet
+	s.Add(
+		fs,
+		New(&host, "host", "Host.", Env("TEST_HOST"), Required()),
+		New(&port, "port", "Port."),
+		New(&workers, "workers", "Workers.", Env("TEST_WORKERS")),
+		New(&token, "token", "Token.", Env("TEST_TOKEN"), Secret(), Validate(Match("^[a-f0-9]+$"))),
+		New(&mode, "mode", "Mode.", Default("fast"), Validate(OneOf("slow"))),
+		New(&cert, "cert", "Cert."),
+		New(&key, "key", "Key."),
+	)
+	s.RequiredTogether("cert", "key")
+
+	require.NoError(t, fs.Parse([]string{"--config", path, "--cert", "c.pem"}))
+
+	err := s.Check()
+	errs := ValidationErrors(err)
+
+	summary := make([]string, len(errs))
+	for i, e := range errs {
+		summary[i] = fmt.Sprintf("%s|%s|%s|%s|%s|%s", e.Reason, e.Name, e.Env, e.Describe(), e.Value, e.Flags)
+	}
+	require.Equal(t, []string{
+		"config|||file " + path + "|bogus|[]",
+		"parse|port||file " + path + "|abc|[]",
+		"missing|host|TEST_HOST|default||[]",
+		"parse|workers|TEST_WORKERS|env TEST_WORKERS|many|[]",
+		"invalid|token|TEST_TOKEN|env TEST_TOKEN|********|[]",
+		"invalid|mode||default|fast|[]",
+		"constraint|cert||default||[cert key]",
+	}, summary)
+
+	// The messages are unchanged.
+	require.Equal(t, `required value "host" not specified`, errs[2].Error())
+
+	data, err := json.Marshal(errs[4])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "token",
+		"env": "TEST_TOKEN",
+		"origin": "env",
+		"key": "TEST_TOKEN",
+		"value": "********",
+		"reason": "invalid",
+		"error": "invalid value for \"token\": invalid value"
+	}`, string(data))
+}
+
+func TestValidationErrorsNil(t *testing.T) {
+	require.Nil(t, ValidationErrors(nil))
+	require.Nil(t, ValidationErrors(fmt.Errorf("plain")))
+}
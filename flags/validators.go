@@ -0,0 +1,234 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Number is the set of types accepted by Range.
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Range returns a validator that requires a numeric value to be between min
+// and max, inclusive. The value is compared exactly, without converting it to
+// T, so Range(1, 65535) can be used with any integer flag, and Range(1, 10)
+// rejects 10.5 and math.MaxUint64. For slice flags, every element is validated.
+func Range[T Number](min, max T) Validator {
+	return rangeValidator[T]{min: min, max: max}
+}
+
+type rangeValidator[T Number] struct {
+	min, max T
+}
+
+func (v rangeValidator[T]) Validate(val any) error {
+	return each(val, func(val any) error {
+		n, ok := exactNumber(reflect.ValueOf(val))
+		if !ok {
+			return fmt.Errorf("%v is not a number", val)
+		}
+
+		min, _ := exactNumber(reflect.ValueOf(v.min))
+		max, _ := exactNumber(reflect.ValueOf(v.max))
+		if n.Cmp(min) < 0 || n.Cmp(max) > 0 {
+			return fmt.Errorf("%v is not between %v and %v", val, v.min, v.max)
+		}
+
+		return nil
+	})
+}
+
+func (v rangeValidator[T]) String() string {
+	return fmt.Sprintf("between %v and %v", v.min, v.max)
+}
+
+// exactNumber converts v to a big.Float without losing precision, so numbers of
+// different types can be compared. ok is false if v isn't a number or is NaN.
+func exactNumber(v reflect.Value) (*big.Float, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Float).SetUint64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) {
+			return nil, false
+		}
+		return new(big.Float).SetFloat64(v.Float()), true
+	}
+	return nil, false
+}
+
+// OneOf returns a validator that requires a string value to be one of the
+// specified values.
+func OneOf(values ...string) Validator {
+	return oneOfValidator(values)
+}
+
+type oneOfValidator []string
+
+func (v oneOfValidator) Validate(val any) error {
+	return eachString(val, func(str string) error {
+		if !slices.Contains(v, str) {
+			return fmt.Errorf("%q is not one of %s", str, strings.Join(v, ", "))
+		}
+		return nil
+	})
+}
+
+func (v oneOfValidator) String() string {
+	return "one of: " + strings.Join(v, ", ")
+}
+
+// Match returns a validator that requires a string value to match the
+// regular expression pattern. It panics if pattern doesn't compile.
+func Match(pattern string) Validator {
+	return matchValidator{re: regexp.MustCompile(pattern)}
+}
+
+type matchValidator struct {
+	re *regexp.Regexp
+}
+
+func (v matchValidator) String() string {
+	return "must match " + v.re.String()
+}
+
+func (v matchValidator) Validate(val any) error {
+	return eachString(val, func(str string) error {
+		if !v.re.MatchString(str) {
+			return fmt.Errorf("%q does not match %s", str, v.re)
+		}
+		return nil
+	})
+}
+
+// NotBlank returns a validator that requires a string value to contain
+// something other than whitespace.
+func NotBlank() Validator {
+	return stringValidator(func(str string) error {
+		if strings.TrimSpace(str) == "" {
+			return errors.New("value is blank")
+		}
+		return nil
+	})
+}
+
+// HostPort returns a validator that requires a string value to be in the form
+// host:port with a valid port number. The host may be empty, e.g. ":8080".
+func HostPort() Validator {
+	return stringValidator(func(str string) error {
+		_, port, err := net.SplitHostPort(str)
+		if err != nil {
+			return err
+		}
+
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("invalid port %q", port)
+		}
+
+		return nil
+	})
+}
+
+// URL returns a validator that requires a string value to be an absolute URL.
+// If any schemes are specified, the URL's scheme must be one of them.
+func URL(schemes ...string) Validator {
+	return stringValidator(func(str string) error {
+		u, err := url.Parse(str)
+		if err != nil {
+			return err
+		}
+
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", str)
+		}
+
+		if len(schemes) > 0 && !slices.Contains(schemes, u.Scheme) {
+			return fmt.Errorf("scheme %q is not one of %s", u.Scheme, strings.Join(schemes, ", "))
+		}
+
+		return nil
+	})
+}
+
+// FileExists returns a validator that requires a string value to be the path
+// of an existing file.
+func FileExists() Validator {
+	return stringValidator(func(str string) error {
+		info, err := os.Stat(str)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return fmt.Errorf("%q is a directory", str)
+		}
+
+		return nil
+	})
+}
+
+// DirExists returns a validator that requires a string value to be the path
+// of an existing directory.
+func DirExists() Validator {
+	return stringValidator(func(str string) error {
+		info, err := os.Stat(str)
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return fmt.Errorf("%q is not a directory", str)
+		}
+
+		return nil
+	})
+}
+
+// stringValidator validates string values, or each element of a []string.
+type stringValidator func(str string) error
+
+func (fn stringValidator) Validate(val any) error {
+	return eachString(val, fn)
+}
+
+// each calls fn for every element of val if val is a slice, otherwise it calls
+// fn with val.
+func each(val any, fn func(any) error) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return fn(val)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := fn(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func eachString(val any, fn func(string) error) error {
+	return each(val, func(val any) error {
+		str, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%v is a %T, not a string", val, val)
+		}
+		return fn(str)
+	})
+}
@@ -0,0 +1,66 @@
+package flags
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidators(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+
+	tests := []struct {
+		desc      string
+		validator Validator
+		val       any
+		err       bool
+	}{
+		{"range ok", Range(1, 10), 5, false},
+		{"range low", Range(1, 10), 0, true},
+		{"range high", Range(1, 10), 11, true},
+		{"range converts", Range(1, 65535), int64(5432), false},
+		{"range float", Range(0.0, 1.0), 0.5, false},
+		{"range not a number", Range(1, 10), "5", true},
+		{"range float above int max", Range(1, 10), 10.5, true},
+		{"range float in int range", Range(1, 10), 5.7, false},
+		{"range large uint", Range(1, 10), uint64(math.MaxUint64), true},
+		{"range negative int uint bounds", Range[uint8](0, 255), -1, true},
+		{"range NaN", Range(0.0, 1.0), math.NaN(), true},
+		{"one of ok", OneOf("a", "b"), "b", false},
+		{"one of bad", OneOf("a", "b"), "c", true},
+		{"one of not a string", OneOf("a", "b"), 1, true},
+		{"match ok", Match(`^[a-z]+-\d$`), "us-1", false},
+		{"match bad", Match(`^[a-z]+-\d$`), "us1", true},
+		{"not blank ok", NotBlank(), " a ", false},
+		{"not blank bad", NotBlank(), " \t", true},
+		{"host port ok", HostPort(), "127.0.0.1:4150", false},
+		{"host port empty host", HostPort(), ":8080", false},
+		{"host port no port", HostPort(), "127.0.0.1", true},
+		{"host port bad port", HostPort(), "127.0.0.1:99999", true},
+		{"url ok", URL(), "https://example.com/path", false},
+		{"url scheme ok", URL("http", "https"), "https://example.com", false},
+		{"url scheme bad", URL("http", "https"), "ftp://example.com", true},
+		{"url relative", URL(), "/path", true},
+		{"file exists ok", FileExists(), file, false},
+		{"file exists dir", FileExists(), dir, true},
+		{"file exists missing", FileExists(), filepath.Join(dir, "nope"), true},
+		{"dir exists ok", DirExists(), dir, false},
+		{"dir exists file", DirExists(), file, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.validator.Validate(tt.val)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package flags
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// value adapts a destination of type *T to pflag.Value, for types pflag has no
+// built-in support for.
+type value[T any] struct {
+	p      *T
+	typ    string
+	parse  func(s string) (T, error)
+	format func(val T) string
+	// display formats the value for display. If nil, format is used.
+	display func(val T) string
+}
+
+func (v *value[T]) Set(s string) error {
+	val, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	*v.p = val
+	return nil
+}
+
+func (v *value[T]) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return v.format(*v.p)
+}
+
+func (v *value[T]) Type() string {
+	return v.typ
+}
+
+// Redacted formats the value for display, hiding any secrets it contains.
+func (v *value[T]) Redacted() string {
+	if v.p == nil || v.display == nil {
+		return v.String()
+	}
+	return v.display(*v.p)
+}
+
+// redacter is implemented by pflag.Values whose String method may reveal a
+// secret, such as the password in a URL. String is used to compare and parse
+// the value, and Redacted wherever the value is shown.
+type redacter interface {
+	Redacted() string
+}
+
+// displayValue formats v for display.
+func displayValue(v pflag.Value) string {
+	if r, ok := v.(redacter); ok {
+		return r.Redacted()
+	}
+	return v.String()
+}
+
+// registerValue registers T, defining flags with a pflag.Value that parses
+// command line values with parse, the same way environment variables are.
+// typ is the name shown for the value in the usage text. display, if not nil,
+// formats the value wherever it is shown instead of format.
+func registerValue[T any](
+	typ string,
+	parse func(f *flag, s string) (T, error),
+	format func(val T) string,
+	display func(val T) string,
+	check func(name string, val T) error,
+) {
+	registerFlagType(func(f *flag) varFunc[T] {
+		return func(fs *pflag.FlagSet, p *T, name string, val T, usage string) {
+			*p = val
+			fs.Var(&value[T]{
+				p:       p,
+				typ:     typ,
+				parse:   func(s string) (T, error) { return parse(f, s) },
+				format:  format,
+				display: display,
+			}, name, usage)
+		}
+	}, parse, check)
+}
+
+// ignoreFlag adapts a parse function that doesn't depend on the flag's options.
+func ignoreFlag[T any](parse func(s string) (T, error)) func(f *flag, s string) (T, error) {
+	return func(_ *flag, s string) (T, error) {
+		return parse(s)
+	}
+}
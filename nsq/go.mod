@@ -6,12 +6,15 @@ require (
 	github.com/jasonhancock/cobraflags/flags v0.0.3
 	github.com/jasonhancock/go-logger v0.0.7
 	github.com/nsqio/go-nsq v1.1.0
-	github.com/spf13/pflag v1.0.5
+	github.com/spf13/pflag v1.0.6
 )
 
 require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
 	github.com/golang/snappy v0.0.1 // indirect
-	github.com/jasonhancock/go-env v0.0.4 // indirect
-	github.com/jasonhancock/go-helpers v0.0.4 // indirect
+	github.com/jasonhancock/go-helpers v0.0.6 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
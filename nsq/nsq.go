@@ -24,9 +24,13 @@ type Config struct {
 	flags.FlagSet
 }
 
-func NewConfig(flagSet *pflag.FlagSet) *Config {
+// NewConfig adds the nsq flags to flagSet. opts are applied to the underlying
+// flags.FlagSet, e.g. to add a prefix to the flag names.
+func NewConfig(flagSet *pflag.FlagSet, opts ...flags.SetOption) *Config {
 	var c Config
 
+	c.Configure(opts...)
+
 	c.Add(
 		flagSet,
 
@@ -36,7 +40,7 @@ func NewConfig(flagSet *pflag.FlagSet) *Config {
 			"The address:port of the nsq server.",
 			flags.Env("NSQ_ADDR"),
 			flags.Default("127.0.0.1:4150"),
-			flags.Required(),
+			flags.RequiredNonZero(),
 		),
 
 		flags.New(
@@ -58,9 +62,12 @@ func NewConfig(flagSet *pflag.FlagSet) *Config {
 			"nsq-ssl-key",
 			"The path to the ssl private key file.",
 			flags.Env("NSQ_SSL_KEY"),
+			flags.Secret(),
 		),
 	)
 
+	c.RequiredTogether("nsq-ssl-cert", "nsq-ssl-key", "nsq-ssl-ca-cert")
+
 	return &c
 }
 
@@ -139,6 +146,10 @@ func (cfg *Config) Consumer(
 }
 
 func (cfg *Config) baseConfig(opts ...Option) (*nsq.Config, *options, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, nil, err
+	}
+
 	var o options
 	for _, opt := range opts {
 		opt(&o)
@@ -5,13 +5,13 @@ go 1.22.1
 require (
 	github.com/jasonhancock/cobraflags/flags v0.0.6
 	github.com/jmoiron/sqlx v1.4.0
-	github.com/spf13/pflag v1.0.5
+	github.com/spf13/pflag v1.0.6
 	github.com/stretchr/testify v1.9.0
 )
 
 require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/jasonhancock/go-env v0.0.6 // indirect
 	github.com/jasonhancock/go-helpers v0.0.6 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c // indirect
@@ -34,78 +34,91 @@ func NewConfig(flagSet *pflag.FlagSet, opts ...Option) *Config {
 		opt(&o)
 	}
 
+	if o.prefix != "" {
+		c.Configure(
+			flags.NamePrefix(strings.ToLower(o.prefix)+"-"),
+			flags.EnvPrefix(strings.ToUpper(o.prefix)+"_"),
+		)
+	}
+	c.Configure(o.flagSetOpts...)
+
 	c.Add(
 		flagSet,
 
 		flags.New(
 			&c.Host,
-			o.flagName("db-host"),
+			"db-host",
 			"Database hostname or IP address",
-			flags.Env(o.envName("DB_HOST")),
+			flags.Env("DB_HOST"),
 			flags.Default("127.0.0.1"),
-			flags.Required(),
+			flags.RequiredNonZero(),
 		),
 
 		flags.New(
 			&c.User,
-			o.flagName("db-user"),
+			"db-user",
 			"Datatabase username",
-			flags.Env(o.envName("DB_USER")),
-			flags.Required(),
+			flags.Env("DB_USER"),
+			flags.RequiredNonZero(),
 		),
 
 		flags.New(
 			&c.Password,
-			o.flagName("db-pass"),
+			"db-pass",
 			"Database password",
-			flags.Env(o.envName("DB_PASSWORD")),
+			flags.Env("DB_PASSWORD"),
+			flags.Secret(),
 		),
 
 		flags.New(
 			&c.Name,
-			o.flagName("db-name"),
+			"db-name",
 			"Database name",
-			flags.Env(o.envName("DB_NAME")),
-			flags.Required(),
+			flags.Env("DB_NAME"),
+			flags.RequiredNonZero(),
 		),
 
 		flags.New(
 			&c.Port,
-			o.flagName("db-port"),
+			"db-port",
 			"Database port",
-			flags.Env(o.envName("DB_PORT")),
+			flags.Env("DB_PORT"),
 			flags.Default(5432),
-			flags.Required(),
+			flags.RequiredNonZero(),
 		),
 
 		flags.New(
 			&c.SSLMode,
-			o.flagName("db-ssl-mode"),
+			"db-ssl-mode",
 			"Database SSL mode",
-			flags.Env(o.envName("DB_SSL_MODE")),
+			flags.Env("DB_SSL_MODE"),
 			flags.Default("disable"),
-			flags.Required(),
+			flags.RequiredNonZero(),
+			flags.Complete(flags.Values("disable", "require", "verify-ca", "verify-full")),
 		),
 
 		flags.New(
 			&c.SSLCert,
-			o.flagName("db-tls-cert"),
+			"db-tls-cert",
 			"TLS client certificate",
-			flags.Env(o.envName("DB_TLS_CERT")),
+			flags.Env("DB_TLS_CERT"),
+			flags.Complete(flags.FileExtensions("pem", "crt")),
 		),
 
 		flags.New(
 			&c.SSLKey,
-			o.flagName("db-tls-key"),
+			"db-tls-key",
 			"TLS client private key",
-			flags.Env(o.envName("DB_TLS_KEY")),
+			flags.Env("DB_TLS_KEY"),
+			flags.Complete(flags.FileExtensions("pem", "key")),
 		),
 
 		flags.New(
 			&c.SSLRootCert,
-			o.flagName("db-tls-ca-cert"),
+			"db-tls-ca-cert",
 			"TLS CA Certificate",
-			flags.Env(o.envName("DB_TLS_CA_CERT")),
+			flags.Env("DB_TLS_CA_CERT"),
+			flags.Complete(flags.FileExtensions("pem", "crt")),
 		),
 	)
 
@@ -164,23 +177,8 @@ func (cfg *Config) Connect() (*sqlx.DB, error) {
 }
 
 type options struct {
-	prefix string
-}
-
-func (o options) flagName(name string) string {
-	if o.prefix == "" {
-		return name
-	}
-
-	return strings.ToLower(o.prefix) + "-" + name
-}
-
-func (o options) envName(name string) string {
-	if o.prefix == "" {
-		return name
-	}
-
-	return strings.ToUpper(o.prefix) + "_" + name
+	prefix      string
+	flagSetOpts []flags.SetOption
 }
 
 // Option is used to customize
@@ -193,3 +191,11 @@ func WithPrefix(prefix string) Option {
 		o.prefix = prefix
 	}
 }
+
+// WithFlagSetOptions passes options through to the underlying flags.FlagSet,
+// e.g. flags.AutoEnv or a custom flags.NamePrefix.
+func WithFlagSetOptions(opts ...flags.SetOption) Option {
+	return func(o *options) {
+		o.flagSetOpts = append(o.flagSetOpts, opts...)
+	}
+}
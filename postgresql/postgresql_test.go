@@ -3,25 +3,46 @@ package postgresql
 import (
 	"testing"
 
+	"github.com/jasonhancock/cobraflags/flags"
+	"github.com/jasonhancock/cobraflags/flags/flagstest"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 )
 
 func TestOptions(t *testing.T) {
+	t.Parallel()
+
 	tests := []struct {
 		prefix       string
 		expectedFlag string
 		expectedEnv  string
 	}{
-		{"", "db", "DB"},
-		{"PreFix", "prefix-db", "PREFIX_DB"},
+		{"", "db-host", "DB_HOST"},
+		{"PreFix", "prefix-db-host", "PREFIX_DB_HOST"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.prefix, func(t *testing.T) {
-			o := options{prefix: tt.prefix}
+			t.Parallel()
+
+			var fs *pflag.FlagSet
+			cfg, _ := flagstest.Parse(t, func(pfs *pflag.FlagSet, opts ...flags.SetOption) *Config {
+				fs = pfs
+				return NewConfig(pfs, WithPrefix(tt.prefix), WithFlagSetOptions(opts...))
+			}, flagstest.WithEnv(map[string]string{tt.expectedEnv: "db.example.com"}))
 
-			require.Equal(t, o.flagName("db"), tt.expectedFlag)
-			require.Equal(t, o.envName("DB"), tt.expectedEnv)
+			require.NotNil(t, fs.Lookup(tt.expectedFlag))
+			require.Equal(t, "db.example.com", cfg.Host)
 		})
 	}
 }
+
+func TestRequiredNonEmpty(t *testing.T) {
+	t.Parallel()
+
+	_, err := flagstest.Parse(t, func(fs *pflag.FlagSet, opts ...flags.SetOption) *Config {
+		return NewConfig(fs, WithFlagSetOptions(opts...))
+	}, flagstest.WithEnv(map[string]string{"DB_USER": "", "DB_NAME": "app"}))
+
+	require.EqualError(t, err, `required value "db-user" not specified`)
+}
@@ -5,8 +5,7 @@ import (
 	"time"
 
 	"github.com/gomodule/redigo/redis"
-	"github.com/jasonhancock/go-env"
-	"github.com/jasonhancock/go-helpers"
+	"github.com/jasonhancock/cobraflags/flags"
 	"github.com/spf13/cobra"
 )
 
@@ -21,30 +20,44 @@ type Config struct {
 	Addr        string
 	DB          int
 	IdleTimeout time.Duration
+
+	flags.FlagSet
 }
 
-func NewConfig(cmd *cobra.Command) *Config {
+// NewConfig adds the redis flags to cmd. opts are applied to the underlying
+// flags.FlagSet, e.g. to add a prefix so that several redis configs can be
+// added to the same command.
+func NewConfig(cmd *cobra.Command, opts ...flags.SetOption) *Config {
 	var c Config
 
-	cmd.Flags().StringVar(
-		&c.Addr,
-		"redis-addr",
-		env.String(EnvAddr, "127.0.0.1:6379"),
-		helpers.EnvDesc("Redis address and port to connect to. Default: 127.0.0.1:6379.", EnvAddr),
-	)
+	c.Configure(opts...)
 
-	cmd.Flags().IntVar(
-		&c.DB,
-		"redis-db",
-		env.Int(EnvDB, 0),
-		helpers.EnvDesc("Redis database number to use. Default: 0.", EnvDB),
-	)
+	c.Add(
+		cmd.Flags(),
+
+		flags.New(
+			&c.Addr,
+			"redis-addr",
+			"Redis address and port to connect to.",
+			flags.Env(EnvAddr),
+			flags.Default("127.0.0.1:6379"),
+		),
+
+		flags.New(
+			&c.DB,
+			"redis-db",
+			"Redis database number to use.",
+			flags.Env(EnvDB),
+			flags.Default(0),
+		),
 
-	cmd.Flags().DurationVar(
-		&c.IdleTimeout,
-		"redis-idle-timeout",
-		env.Duration(EnvIdleTimeout, DefaultIdleTimeout),
-		helpers.EnvDesc("Redis idle timeout to use.", EnvIdleTimeout),
+		flags.New(
+			&c.IdleTimeout,
+			"redis-idle-timeout",
+			"Redis idle timeout to use.",
+			flags.Env(EnvIdleTimeout),
+			flags.Default(DefaultIdleTimeout),
+		),
 	)
 
 	return &c
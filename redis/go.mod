@@ -4,12 +4,15 @@ go 1.22.0
 
 require (
 	github.com/gomodule/redigo v1.8.9
-	github.com/jasonhancock/go-env v0.0.3
-	github.com/jasonhancock/go-helpers v0.0.4
+	github.com/jasonhancock/cobraflags/flags v0.0.3
 	github.com/spf13/cobra v1.8.0
 )
 
 require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/jasonhancock/go-helpers v0.0.6 // indirect
+	github.com/spf13/pflag v1.0.6 // indirect
+	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
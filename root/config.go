@@ -0,0 +1,386 @@
+package root
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jasonhancock/cobraflags/flags"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+type registration struct {
+	cmd  *cobra.Command
+	sets []*flags.FlagSet
+}
+
+// Register records the flag sets whose flags were added to cmd. Before cmd, or
+// any of its subcommands, runs, Check is called on each of them and the
+// failures are reported together. The flag sets can also be inspected by the
+// commands added by WithConfigCommands.
+//
+// The checks are run by the root command's PersistentPreRunE, which cobra
+// doesn't run for commands that define their own PersistentPreRun or
+// PersistentPreRunE.
+//
+// A config file is usually shared by all of the commands, so keys in it are
+// accepted if they match a flag of any command in the tree.
+//
+// The value completions of the flags, set with flags.Complete, are registered
+// with cmd, so the flags must already have been added to it. Flags that already
+// have a completion function registered keep it. An error is returned for the
+// completions that couldn't be registered, e.g. because the flag was added to a
+// different command.
+func (c *Command) Register(cmd *cobra.Command, sets ...*flags.FlagSet) error {
+	c.registrations = append(c.registrations, registration{cmd: cmd, sets: sets})
+
+	var errs []error
+	for _, s := range sets {
+		s.Configure(flags.KnownConfigKeys(c.isFlag))
+		s.VisitCompletions(func(name string, comp flags.Completion) {
+			if _, ok := cmd.GetFlagCompletionFunc(name); ok {
+				return
+			}
+			if err := cmd.RegisterFlagCompletionFunc(name, completionFunc(comp)); err != nil {
+				errs = append(errs, err)
+			}
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// isFlag reports whether name is a flag of any command in the tree.
+func (c *Command) isFlag(name string) bool {
+	var found bool
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+			found = true
+			return
+		}
+		for _, sub := range cmd.Commands() {
+			if !found {
+				visit(sub)
+			}
+		}
+	}
+	visit(c.root)
+	return found
+}
+
+// completionFunc converts a flag's completion into a cobra completion function.
+func completionFunc(comp flags.Completion) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case comp.Func != nil:
+			return comp.Func(toComplete), cobra.ShellCompDirectiveNoFileComp
+		case len(comp.Extensions) > 0:
+			return comp.Extensions, cobra.ShellCompDirectiveFilterFileExt
+		case comp.DirsOnly:
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		default:
+			return comp.Values, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+// installPreRun sets the root command's PersistentPreRunE to check the flag
+// sets registered for the command being run, after any pre-run function the
+// root command already had.
+func (c *Command) installPreRun() {
+	preRun, preRunE := c.root.PersistentPreRun, c.root.PersistentPreRunE
+	c.root.PersistentPreRun = nil
+	c.root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if preRunE != nil {
+			if err := preRunE(cmd, args); err != nil {
+				return err
+			}
+		} else if preRun != nil {
+			preRun(cmd, args)
+		}
+
+		if err := c.check(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		return nil
+	}
+}
+
+// check calls Check on the flag sets registered for cmd and its parents. The
+// config commands are skipped so that they can be used to debug an invalid
+// configuration.
+func (c *Command) check(cmd *cobra.Command) error {
+	for p := cmd; p != nil; p = p.Parent() {
+		if p == c.configCmd {
+			return nil
+		}
+	}
+
+	var errs []error
+	for _, s := range c.setsFor(cmd) {
+		if err := s.Check(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &configError{errs: errs}
+}
+
+// setsFor returns the flag sets registered for cmd and its parents.
+func (c *Command) setsFor(cmd *cobra.Command) []*flags.FlagSet {
+	var sets []*flags.FlagSet
+	seen := make(map[*flags.FlagSet]bool)
+	for p := cmd; p != nil; p = p.Parent() {
+		for _, r := range c.registrations {
+			if r.cmd != p {
+				continue
+			}
+			for _, s := range r.sets {
+				if !seen[s] {
+					seen[s] = true
+					sets = append(sets, s)
+				}
+			}
+		}
+	}
+	return sets
+}
+
+// WatchConfig reloads the flag sets registered for cmd and its parents when
+// the process receives SIGHUP or a config file changes, until cmd's context is
+// done. Only flags created with flags.Reloadable change. Failed reloads are
+// logged with the logger returned by Logger, if it has been created, or written
+// to stderr otherwise. Additional options are passed to flags.FlagSet.Watch.
+func (c *Command) WatchConfig(cmd *cobra.Command, opts ...flags.WatchOption) {
+	opts = append([]flags.WatchOption{
+		flags.OnReloadError(func(err error) {
+			if c.logger != nil {
+				c.logger.LogError("configuration reload failed", err)
+				return
+			}
+			fmt.Fprintln(os.Stderr, err)
+		}),
+	}, opts...)
+
+	for _, s := range c.setsFor(cmd) {
+		go s.Watch(cmd.Context(), opts...)
+	}
+}
+
+// configError lists every problem found with the configuration, one per line.
+type configError struct {
+	errs []error
+}
+
+func (e *configError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, line := range strings.Split(errors.Join(e.errs...).Error(), "\n") {
+		b.WriteString("\n  - " + line)
+	}
+	return b.String()
+}
+
+func (e *configError) Unwrap() []error {
+	return e.errs
+}
+
+// flagSets returns every registered flag set once, in the order they were
+// registered. Persistent flags of the commands' parents, such as the flag added
+// by flags.AddConfigFlag, are merged into the commands' flags so that values
+// given to the config subcommands are seen by the flag sets.
+func (c *Command) flagSets() []*flags.FlagSet {
+	var sets []*flags.FlagSet
+	seen := make(map[*flags.FlagSet]bool)
+	for _, r := range c.registrations {
+		mergePersistentFlags(r.cmd)
+		for _, s := range r.sets {
+			if !seen[s] {
+				seen[s] = true
+				sets = append(sets, s)
+			}
+		}
+	}
+	return sets
+}
+
+// mergePersistentFlags adds the persistent flags of cmd and its parents to
+// cmd's flags, as cobra does for the command being run. The config commands
+// inspect the flag sets of commands that aren't being run, whose flags
+// wouldn't otherwise include e.g. a --config flag defined on the root command.
+// The flags are shared, so values parsed for the config command are seen.
+func mergePersistentFlags(cmd *cobra.Command) {
+	for p := cmd; p != nil; p = p.Parent() {
+		p.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+			if cmd.Flags().Lookup(f.Name) == nil {
+				cmd.Flags().AddFlag(f)
+			}
+		})
+	}
+}
+
+func newConfigCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration.",
+	}
+
+	cmd.AddCommand(
+		newConfigShowCmd(c),
+		newConfigValidateCmd(c),
+		newConfigEnvCmd(c),
+	)
+
+	return cmd
+}
+
+type configValue struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Origin string `json:"origin" yaml:"origin"`
+	Key    string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+func newConfigShowCmd(c *Command) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:          "show",
+		Short:        "Prints the effective configuration.",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var values []flags.EffectiveValue
+			var errs []error
+			for _, s := range c.flagSets() {
+				if err := s.Load(); err != nil {
+					errs = append(errs, err)
+				}
+				values = append(values, s.Effective()...)
+			}
+			if err := errors.Join(errs...); err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+
+			switch output {
+			case "table":
+				return flags.PrintEffectiveValues(w, values)
+			case "json", "yaml":
+				list := make([]configValue, 0, len(values))
+				for _, v := range values {
+					list = append(list, configValue{
+						Name:   v.Name,
+						Value:  v.Value,
+						Origin: v.Origin.String(),
+						Key:    v.Key,
+					})
+				}
+
+				if output == "yaml" {
+					return yaml.NewEncoder(w).Encode(list)
+				}
+
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(list)
+			}
+
+			return fmt.Errorf("unsupported output format %q", output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format. One of table, json or yaml.")
+
+	return cmd
+}
+
+func newConfigValidateCmd(c *Command) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:          "validate",
+		Short:        "Validates the configuration.",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return fmt.Errorf("unsupported output format %q", output)
+			}
+
+			var errs []error
+			for _, s := range c.flagSets() {
+				if err := s.Check(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			err := errors.Join(errs...)
+			if err == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+				return nil
+			}
+
+			verrs := flags.ValidationErrors(err)
+			if len(verrs) == 0 {
+				return err
+			}
+
+			if err := printValidationErrors(cmd.OutOrStdout(), output, verrs); err != nil {
+				return err
+			}
+			return errors.New("configuration is invalid")
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format. One of table or json.")
+
+	return cmd
+}
+
+func printValidationErrors(w io.Writer, output string, errs []*flags.ValidationError) error {
+	if output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(errs)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tENV\tREASON\tSOURCE\tVALUE\tERROR")
+	for _, e := range errs {
+		name := e.Name
+		if len(e.Flags) > 1 {
+			name = strings.Join(e.Flags, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", name, e.Env, e.Reason, e.Describe(), e.Value, e.Err)
+	}
+	return tw.Flush()
+}
+
+func newConfigEnvCmd(c *Command) *cobra.Command {
+	return &cobra.Command{
+		Use:          "env",
+		Short:        "Prints a .env template listing every environment variable.",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, s := range c.flagSets() {
+				if err := s.PrintEnv(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,289 @@
+package root
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jasonhancock/cobraflags/flags"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Host     string
+	Password string
+	Port     int
+
+	flags.FlagSet
+}
+
+func newTestCommand(t *testing.T) *Command {
+	t.Helper()
+
+	server := &cobra.Command{
+		Use:  "server",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+
+	var cfg testConfig
+	cfg.Add(
+		server.Flags(),
+		flags.New(&cfg.Host, "db-host", "Database host.", flags.Env("TEST_DB_HOST"), flags.Required()),
+		flags.New(&cfg.Password, "db-pass", "Database password.", flags.Env("TEST_DB_PASS"), flags.Secret()),
+		flags.New(&cfg.Port, "db-port", "Database port.", flags.Env("TEST_DB_PORT"), flags.Default(5432)),
+	)
+
+	r := New("myapp", WithConfigCommands(), WithCommand(server))
+	flags.AddConfigFlag(r.root.PersistentFlags())
+	require.NoError(t, r.Register(server, &cfg.FlagSet))
+
+	return r
+}
+
+func runCommand(r *Command, args ...string) (string, error) {
+	var buf bytes.Buffer
+	r.root.SetOut(&buf)
+	r.root.SetArgs(args)
+	err := r.root.Execute()
+	return buf.String(), err
+}
+
+func TestConfigShow(t *testing.T) {
+	t.Setenv("TEST_DB_PASS", "hunter2")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("db-host: db.example.com\n"), 0o600))
+
+	t.Run("table", func(t *testing.T) {
+		out, err := runCommand(newTestCommand(t), "config", "show", "--config", path)
+		require.NoError(t, err)
+		require.Equal(t, `FLAG     VALUE           SOURCE
+db-host  db.example.com  file `+path+`
+db-pass  ********        env TEST_DB_PASS
+db-port  5432            default
+`, out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := runCommand(newTestCommand(t), "config", "show", "-o", "json")
+		require.NoError(t, err)
+		require.JSONEq(t, `[
+			{"name": "db-host", "value": "", "origin": "default"},
+			{"name": "db-pass", "value": "********", "origin": "env", "key": "TEST_DB_PASS"},
+			{"name": "db-port", "value": "5432", "origin": "default"}
+		]`, out)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		out, err := runCommand(newTestCommand(t), "config", "show", "-o", "yaml")
+		require.NoError(t, err)
+		require.Contains(t, out, "- name: db-pass\n  value: '********'\n  origin: env\n  key: TEST_DB_PASS\n")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := runCommand(newTestCommand(t), "config", "show", "-o", "xml")
+		require.EqualError(t, err, `unsupported output format "xml"`)
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Setenv("TEST_DB_PORT", "abc")
+
+	out, err := runCommand(newTestCommand(t), "config", "validate")
+	require.EqualError(t, err, "configuration is invalid")
+	require.Equal(t, `FLAG     ENV           REASON   SOURCE            VALUE  ERROR
+db-host  TEST_DB_HOST  missing  default                  required value "db-host" not specified
+db-port  TEST_DB_PORT  parse    env TEST_DB_PORT  abc    parsing environment variable TEST_DB_PORT for "db-port": strconv.ParseInt: parsing "abc": invalid syntax
+`, out)
+
+	out, err = runCommand(newTestCommand(t), "config", "validate", "-o", "json")
+	require.EqualError(t, err, "configuration is invalid")
+	require.JSONEq(t, `[
+		{"name": "db-host", "env": "TEST_DB_HOST", "origin": "default", "reason": "missing", "error": "required value \"db-host\" not specified"},
+		{"name": "db-port", "env": "TEST_DB_PORT", "origin": "env", "key": "TEST_DB_PORT", "value": "abc", "reason": "parse", "error": "parsing environment variable TEST_DB_PORT for \"db-port\": strconv.ParseInt: parsing \"abc\": invalid syntax"}
+	]`, out)
+
+	t.Setenv("TEST_DB_PORT", "5432")
+	t.Setenv("TEST_DB_HOST", "db.example.com")
+	out, err = runCommand(newTestCommand(t), "config", "validate")
+	require.NoError(t, err)
+	require.Equal(t, "configuration is valid\n", out)
+}
+
+func TestConfigEnv(t *testing.T) {
+	out, err := runCommand(newTestCommand(t), "config", "env")
+	require.NoError(t, err)
+	require.Equal(t, `# --db-host: Database host. (required)
+TEST_DB_HOST=
+
+# --db-pass: Database password.
+TEST_DB_PASS=
+
+# --db-port: Database port.
+TEST_DB_PORT=5432
+
+`, out)
+}
+
+func TestPreRunCheck(t *testing.T) {
+	t.Setenv("TEST_DB_PORT", "abc")
+
+	_, err := runCommand(newTestCommand(t), "server")
+	require.EqualError(t, err, `invalid configuration:
+  - required value "db-host" not specified
+  - parsing environment variable TEST_DB_PORT for "db-port": strconv.ParseInt: parsing "abc": invalid syntax`)
+
+	// The config commands still work with an invalid configuration.
+	_, err = runCommand(newTestCommand(t), "config", "show")
+	require.NoError(t, err)
+
+	t.Setenv("TEST_DB_HOST", "db.example.com")
+	t.Setenv("TEST_DB_PORT", "5433")
+	_, err = runCommand(newTestCommand(t), "server")
+	require.NoError(t, err)
+}
+
+func TestPreRunCheckKeepsExistingPreRun(t *testing.T) {
+	var called bool
+	base := &cobra.Command{
+		Use:           "myapp",
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			called = true
+		},
+	}
+	server := &cobra.Command{
+		Use:  "server",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+
+	var host string
+	var s flags.FlagSet
+	s.Add(server.Flags(), flags.New(&host, "db-host", "Database host.", flags.Required()))
+
+	r := New("myapp", WithBaseCommand(base), WithCommand(server))
+	require.NoError(t, r.Register(server, &s))
+
+	_, err := runCommand(r, "server")
+	require.EqualError(t, err, "invalid configuration:\n  - required value \"db-host\" not specified")
+	require.True(t, called)
+
+	_, err = runCommand(r, "server", "--db-host", "db.example.com")
+	require.NoError(t, err)
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("workers: 1\n"), 0o600))
+
+	var workers int
+	var s flags.FlagSet
+	changed := make(chan []flags.Change, 1)
+	s.Subscribe(func(changes []flags.Change) { changed <- changes })
+
+	var r *Command
+	server := &cobra.Command{
+		Use: "server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r.WatchConfig(cmd, flags.WatchSignals(), flags.WatchInterval(10*time.Millisecond))
+
+			require.NoError(t, os.WriteFile(path, []byte("workers: 2\n"), 0o600))
+
+			select {
+			case changes := <-changed:
+				require.Equal(t, []flags.Change{{Name: "workers", Old: 1, New: 2}}, changes)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the reload")
+			}
+			return nil
+		},
+	}
+	s.Add(server.Flags(), flags.New(&workers, "workers", "Workers.", flags.Reloadable()))
+
+	r = New("myapp", WithCommand(server))
+	flags.AddConfigFlag(r.root.PersistentFlags())
+	require.NoError(t, r.Register(server, &s))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r.root.SetArgs([]string{"server", "--config", path})
+	require.NoError(t, r.root.ExecuteContext(ctx))
+	s.Read(func() {
+		require.Equal(t, 2, workers)
+	})
+}
+
+func TestCompletions(t *testing.T) {
+	server := &cobra.Command{
+		Use:  "server",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+
+	var mode, cert, dir string
+	var s flags.FlagSet
+	s.Add(
+		server.Flags(),
+		flags.New(&mode, "db-ssl-mode", "SSL mode.", flags.Complete(flags.Values("disable", "require", "verify-ca", "verify-full"))),
+		flags.New(&cert, "db-tls-cert", "Certificate.", flags.Complete(flags.FileExtensions("pem", "crt"))),
+		flags.New(&dir, "data-dir", "Data directory.", flags.Complete(flags.Dirs())),
+	)
+
+	r := New("myapp", WithCommand(server))
+	require.NoError(t, r.Register(server, &s))
+
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"--db-ssl-mode", "disable\nrequire\nverify-ca\nverify-full\n:4\n"},
+		{"--db-tls-cert", "pem\ncrt\n:8\n"},
+		{"--data-dir", ":16\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			out, err := runCommand(r, cobra.ShellCompRequestCmd, "server", tt.flag, "")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestCompletionsUnknownFlag(t *testing.T) {
+	server := &cobra.Command{Use: "server"}
+	other := &cobra.Command{Use: "other"}
+
+	var mode string
+	var s flags.FlagSet
+	s.Add(other.Flags(), flags.New(&mode, "db-ssl-mode", "SSL mode.", flags.Complete(flags.Values("disable", "require"))))
+
+	r := New("myapp", WithCommand(server), WithCommand(other))
+	require.EqualError(t, r.Register(server, &s), "RegisterFlagCompletionFunc: flag 'db-ssl-mode' does not exist")
+}
+
+func TestSharedConfigKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("db-host: db1\nqueue: jobs\ndb-hots: db2\n"), 0o600))
+
+	noop := func(cmd *cobra.Command, args []string) error { return nil }
+	server := &cobra.Command{Use: "server", RunE: noop}
+	worker := &cobra.Command{Use: "worker", RunE: noop}
+
+	var host, queue string
+	var serverFlags, workerFlags flags.FlagSet
+	serverFlags.Add(server.Flags(), flags.New(&host, "db-host", "Database host."))
+	workerFlags.Add(worker.Flags(), flags.New(&queue, "queue", "Queue."))
+
+	r := New("myapp", WithCommand(server), WithCommand(worker))
+	flags.AddConfigFlag(r.root.PersistentFlags())
+	require.NoError(t, r.Register(server, &serverFlags))
+	require.NoError(t, r.Register(worker, &workerFlags))
+
+	// The worker's key is accepted, but the typo isn't.
+	_, err := runCommand(r, "server", "--config", path)
+	require.EqualError(t, err, "invalid configuration:\n  - "+path+`: unknown key "db-hots"`)
+}
@@ -5,12 +5,16 @@ go 1.22.0
 require (
 	github.com/jasonhancock/cobra-logger v0.0.9
 	github.com/jasonhancock/cobra-version v0.0.5
+	github.com/jasonhancock/cobraflags/flags v0.0.3
 	github.com/jasonhancock/go-logger v0.0.8
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
 	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.6 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
@@ -19,6 +23,5 @@ require (
 	github.com/jasonhancock/go-helpers v0.0.9 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c // indirect
 )
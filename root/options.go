@@ -6,12 +6,13 @@ import (
 )
 
 type options struct {
-	cmd           *cobra.Command
-	short         string
-	long          string
-	version       *ver.Info
-	commands      []*cobra.Command
-	loggerEnabled bool
+	cmd            *cobra.Command
+	short          string
+	long           string
+	version        *ver.Info
+	commands       []*cobra.Command
+	loggerEnabled  bool
+	configCommands bool
 }
 
 // Option is used to customize the command.
@@ -60,6 +61,14 @@ func LoggerEnabled(enabled bool) Option {
 	}
 }
 
+// WithConfigCommands adds the "config show", "config validate" and "config env"
+// commands, which operate on the flag sets passed to Command.Register.
+func WithConfigCommands() Option {
+	return func(o *options) {
+		o.configCommands = true
+	}
+}
+
 type loggerOptions struct {
 	name    string
 	keyvals []any
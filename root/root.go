@@ -12,6 +12,7 @@ import (
 
 	clog "github.com/jasonhancock/cobra-logger"
 	ver "github.com/jasonhancock/cobra-version"
+	"github.com/jasonhancock/cobraflags/flags"
 	"github.com/jasonhancock/go-logger"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +28,9 @@ type Command struct {
 	loggerConfig *clog.Config
 	logger       *logger.L
 	Version      *ver.Info
+
+	registrations []registration
+	configCmd     *cobra.Command
 }
 
 func New(use string, opts ...Option) *Command {
@@ -55,6 +59,13 @@ func New(use string, opts ...Option) *Command {
 
 	c.root.AddCommand(o.commands...)
 
+	if o.configCommands {
+		c.configCmd = newConfigCmd(&c)
+		c.root.AddCommand(c.configCmd)
+	}
+
+	c.installPreRun()
+
 	if o.loggerEnabled {
 		c.loggerConfig = clog.NewConfigPflags(
 			strings.Fields(use)[0],
@@ -75,7 +86,21 @@ func (c *Command) Execute() {
 			if c.logger == nil {
 				c.logger = c.loggerConfig.Logger(os.Stderr)
 			}
-			c.logger.LogError("execution error", err)
+			if errs := flags.ValidationErrors(err); len(errs) > 0 {
+				for _, ve := range errs {
+					c.logger.Err(
+						"invalid configuration",
+						"flag", ve.Name,
+						"env", ve.Env,
+						"source", ve.Describe(),
+						"value", ve.Value,
+						"reason", ve.Reason,
+						"error", ve.Err.Error(),
+					)
+				}
+			} else {
+				c.logger.LogError("execution error", err)
+			}
 		} else {
 			fmt.Println(err)
 		}